@@ -0,0 +1,267 @@
+// Package app runs the long-lived components of a service, such as servers,
+// workers and schedulers, and shuts them down in dependency order.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Runnable is a component managed by a Runner. Run blocks until the component
+// stops. It should return nil or ctx.Err() once ctx is canceled; any other
+// error returned before shutdown stops the whole application.
+type Runnable interface {
+	Run(ctx context.Context) error
+}
+
+// Shutdowner is implemented by components that stop through a method rather
+// than by their context being canceled. Shutdown is called before the
+// component's context is canceled.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Readier is implemented by components that take a while to become usable.
+// Components depending on one are started only after Ready returns nil.
+type Readier interface {
+	Ready(ctx context.Context) error
+}
+
+// RunFunc adapts a function to Runnable.
+type RunFunc func(ctx context.Context) error
+
+// Run calls f(ctx).
+func (f RunFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Service adapts a component that starts working when it is created and only
+// needs to be shut down, such as a pool.Pool or sched.Scheduler. Its Run
+// blocks until the context is canceled.
+func Service(s Shutdowner) Runnable {
+	return service{s}
+}
+
+type service struct {
+	Shutdowner
+}
+
+func (service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Config configures a Runner.
+type Config struct {
+	// ShutdownTimeout bounds the shutdown of all components together.
+	// Defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Runner starts components after the components they depend on and stops
+// them in reverse order.
+type Runner struct {
+	timeout    time.Duration
+	logger     *slog.Logger
+	components []*component
+	byName     map[string]*component
+	err        error
+}
+
+type component struct {
+	name      string
+	r         Runnable
+	dependsOn []string
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopping atomic.Bool
+	err      error
+}
+
+// failure is the error of a component that stopped on its own.
+type failure struct {
+	name string
+	err  error
+}
+
+func (f *failure) Error() string {
+	return f.name + ": " + f.err.Error()
+}
+
+func (f *failure) Unwrap() error {
+	return f.err
+}
+
+// New returns an empty Runner.
+func New(cfg Config) *Runner {
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Runner{
+		timeout: cfg.ShutdownTimeout,
+		logger:  cfg.Logger,
+		byName:  make(map[string]*component),
+	}
+}
+
+// Add registers r under name. It is started after the components named in
+// dependsOn and stopped before them. Components are otherwise started in the
+// order they are added.
+func (r *Runner) Add(name string, c Runnable, dependsOn ...string) {
+	if _, ok := r.byName[name]; ok {
+		r.err = errors.Join(r.err,
+			fmt.Errorf("component %q added more than once", name))
+		return
+	}
+	comp := &component{name: name, r: c, dependsOn: dependsOn}
+	r.components = append(r.components, comp)
+	r.byName[name] = comp
+}
+
+// Run starts the components and blocks until ctx is done or a component
+// fails, then shuts all started components down. It returns the error of the
+// failed component joined with any shutdown errors. Pass signals.Context() as
+// ctx to stop on SIGTERM.
+func (r *Runner) Run(ctx context.Context) error {
+	order, err := r.order()
+	if err != nil {
+		return err
+	}
+
+	ctx, fail := context.WithCancelCause(ctx)
+	defer fail(nil)
+	var started []*component
+	for _, c := range order {
+		if err := r.waitReady(ctx, c); err != nil {
+			fail(&failure{name: c.name, err: err})
+			break
+		}
+		r.start(ctx, c, fail)
+		started = append(started, c)
+	}
+	<-ctx.Done()
+
+	var errs []error
+	var f *failure
+	if errors.As(context.Cause(ctx), &f) {
+		r.logger.Error("component failed", "name", f.name, "error", f.err)
+		errs = append(errs, f)
+	}
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		start := time.Now()
+		if err := c.stop(sctx); err != nil {
+			r.logger.Error("component shutdown failed", "name", c.name,
+				"duration", time.Since(start), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		r.logger.Info("component stopped", "name", c.name,
+			"duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Runner) waitReady(ctx context.Context, c *component) error {
+	for _, dep := range c.dependsOn {
+		if rd, ok := r.byName[dep].r.(Readier); ok {
+			if err := rd.Ready(ctx); err != nil {
+				return fmt.Errorf("waiting for %s: %w", dep, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Runner) start(ctx context.Context, c *component,
+	fail context.CancelCauseFunc) {
+	c.ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	c.done = make(chan struct{})
+	r.logger.Info("starting component", "name", c.name)
+	go func() {
+		defer close(c.done)
+		err := c.r.Run(c.ctx)
+		if c.stopping.Load() {
+			if !errors.Is(err, context.Canceled) {
+				c.err = err
+			}
+			return
+		}
+		if err != nil {
+			fail(&failure{name: c.name, err: err})
+			return
+		}
+		r.logger.Info("component exited", "name", c.name)
+	}()
+}
+
+func (c *component) stop(ctx context.Context) error {
+	c.stopping.Store(true)
+	var err error
+	if s, ok := c.r.(Shutdowner); ok {
+		err = s.Shutdown(ctx)
+	}
+	c.cancel()
+	select {
+	case <-c.done:
+		return errors.Join(err, c.err)
+	case <-ctx.Done():
+		return errors.Join(err, ctx.Err())
+	}
+}
+
+// order returns the components sorted so that each comes after its
+// dependencies.
+func (r *Runner) order() ([]*component, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[*component]int)
+	var order []*component
+	var visit func(c *component) error
+	visit = func(c *component) error {
+		switch state[c] {
+		case visiting:
+			return fmt.Errorf("dependency cycle at component %q", c.name)
+		case visited:
+			return nil
+		}
+		state[c] = visiting
+		for _, name := range c.dependsOn {
+			dep, ok := r.byName[name]
+			if !ok {
+				return fmt.Errorf("component %q depends on unknown %q",
+					c.name, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[c] = visited
+		order = append(order, c)
+		return nil
+	}
+	for _, c := range r.components {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,265 @@
+// Package cache provides an in-memory cache with expiry, LRU eviction and
+// loaders that prevent stampedes.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config configures a Cache. The zero value never expires or evicts entries.
+type Config struct {
+	// TTL is how long entries live. Zero means forever.
+	TTL time.Duration
+	// MaxSize is the number of entries kept before the least recently used
+	// one is evicted. Zero means unbounded.
+	MaxSize int
+	// CleanupInterval is how often expired entries are removed in the
+	// background. Zero disables the janitor; expired entries are then only
+	// dropped when they are read or evicted.
+	CleanupInterval time.Duration
+}
+
+// Stats is a snapshot of the cache's counters.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	val     V
+	expires time.Time
+}
+
+type call[V any] struct {
+	done     chan struct{}
+	val      V
+	err      error
+	panicked *panicError
+}
+
+// panicError is the value callers waiting on a panicking loader panic with.
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("cache loader panicked: %v\n\n%s", p.value, p.stack)
+}
+
+// Cache is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl     time.Duration
+	maxSize int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[K]*list.Element
+	calls map[K]*call[V]
+
+	hits, misses, evictions atomic.Int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// New returns a cache configured by cfg, starting the janitor if enabled.
+func New[K comparable, V any](cfg Config) *Cache[K, V] {
+	c := &Cache[K, V]{
+		ttl:     cfg.TTL,
+		maxSize: cfg.MaxSize,
+		ll:      list.New(),
+		items:   make(map[K]*list.Element),
+		calls:   make(map[K]*call[V]),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if cfg.CleanupInterval > 0 {
+		go c.janitor(cfg.CleanupInterval)
+	} else {
+		close(c.done)
+	}
+	return c
+}
+
+// Get returns the value for key if it is present and not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if e.expires.IsZero() || time.Now().Before(e.expires) {
+			c.ll.MoveToFront(el)
+			c.hits.Add(1)
+			return e.val, true
+		}
+		c.remove(el)
+	}
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set stores val under key with the configured TTL.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithTTL(key, val, c.ttl)
+}
+
+// SetWithTTL stores val under key, expiring after ttl, or never if ttl is
+// zero.
+func (c *Cache[K, V]) SetWithTTL(key K, val V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.val, e.expires = val, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry[K, V]{key: key, val: val,
+		expires: expires})
+	if c.maxSize > 0 && c.ll.Len() > c.maxSize {
+		c.remove(c.ll.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes key.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+/*
+GetOrLoad returns the cached value for key, or calls load and caches its
+result. Concurrent callers missing the same key share a single load, so an
+expired hot key does not send a stampede to the backend. Errors are returned
+to every waiting caller and not cached. If load panics, every waiting caller
+panics too and the next call loads again.
+*/
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K,
+	load func(context.Context) (V, error)) (V, error) {
+	if val, ok := c.Get(key); ok {
+		return val, nil
+	}
+
+	c.mu.Lock()
+	cl, loading := c.calls[key]
+	if !loading {
+		cl = &call[V]{done: make(chan struct{})}
+		c.calls[key] = cl
+	}
+	c.mu.Unlock()
+
+	if !loading {
+		c.load(ctx, key, cl, load)
+		if cl.panicked != nil {
+			panic(cl.panicked)
+		}
+		return cl.val, cl.err
+	}
+
+	select {
+	case <-cl.done:
+		if cl.panicked != nil {
+			panic(cl.panicked)
+		}
+		return cl.val, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, cl *call[V],
+	load func(context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			cl.panicked = &panicError{value: r, stack: debug.Stack()}
+		}
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+	cl.val, cl.err = load(ctx)
+	if cl.err == nil {
+		c.Set(key, cl.val)
+	}
+}
+
+// Stats returns the current counters.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      c.Len(),
+	}
+}
+
+// Shutdown stops the janitor. The cache stays usable afterwards.
+func (c *Cache[K, V]) Shutdown(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.removeExpired()
+		}
+	}
+}
+
+func (c *Cache[K, V]) removeExpired() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, el := range c.items {
+		e := el.Value.(*entry[K, V])
+		if !e.expires.IsZero() && now.After(e.expires) {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := New[string, int](Config{})
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+
+	// getOrLoad calls GetOrLoad and returns what it panicked with.
+	getOrLoad := func(load func(context.Context) (int, error)) (r any) {
+		defer func() { r = recover() }()
+		c.GetOrLoad(ctx, "k", load)
+		return nil
+	}
+	const waiters = 5
+	panics := make(chan any, waiters+1)
+	go func() {
+		panics <- getOrLoad(func(context.Context) (int, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	var wg sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			panics <- getOrLoad(func(context.Context) (int, error) {
+				t.Error("a waiter's loader ran while the first was loading")
+				return 0, nil
+			})
+		}()
+	}
+	// Let the waiters join the running load.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for i := 0; i < waiters+1; i++ {
+		r := <-panics
+		p, ok := r.(*panicError)
+		if !ok || p.value != "boom" ||
+			!strings.Contains(p.Error(), "cache loader panicked: boom") {
+			t.Errorf("GetOrLoad panicked with %v; want the loader's panic", r)
+		}
+	}
+
+	// The key isn't stuck loading.
+	val, err := c.GetOrLoad(ctx, "k", func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || val != 42 {
+		t.Fatalf("GetOrLoad after a panic = %d, %v; want 42, nil", val, err)
+	}
+	if val, ok := c.Get("k"); !ok || val != 42 {
+		t.Errorf("Get = %d, %v; want 42, true", val, ok)
+	}
+}
@@ -0,0 +1,210 @@
+// Package client builds HTTP clients with sane timeouts, retries for
+// idempotent requests and request logging.
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/AnuragThePathak/my-go-packages/retry"
+)
+
+// Config configures a Client. Zero values are replaced by the defaults noted
+// on each field.
+type Config struct {
+	// Timeout bounds a whole request including retries. Defaults to 30s.
+	Timeout time.Duration
+	// DialTimeout defaults to 5s.
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period. Defaults to 30s.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout defaults to 5s.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout is unlimited by default.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout defaults to 90s.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns defaults to 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost defaults to 10.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost is unlimited by default.
+	MaxConnsPerHost int
+	// Retries is the number of times an idempotent request is retried after
+	// a connection error or a 502, 503 or 504 response.
+	Retries int
+	// RetryBackoff is the delay before the first retry. Defaults to 100ms.
+	RetryBackoff time.Duration
+	// Logger, if set, receives a debug record for every request and a
+	// warning for every failed one.
+	Logger *slog.Logger
+}
+
+// Stats is a snapshot of the client's counters.
+type Stats struct {
+	Requests int64
+	Failures int64
+	Retries  int64
+}
+
+// Client is an *http.Client whose idle connections are closed on shutdown.
+type Client struct {
+	*http.Client
+	transport *http.Transport
+	rt        *roundTripper
+}
+
+// New returns a client configured by cfg.
+func New(cfg Config) *Client {
+	orDefault := func(d, def time.Duration) time.Duration {
+		if d == 0 {
+			return def
+		}
+		return d
+	}
+	orDefaultInt := func(n, def int) int {
+		if n == 0 {
+			return def
+		}
+		return n
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   orDefault(cfg.DialTimeout, 5*time.Second),
+		KeepAlive: orDefault(cfg.KeepAlive, 30*time.Second),
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   orDefault(cfg.TLSHandshakeTimeout, 5*time.Second),
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       orDefault(cfg.IdleConnTimeout, 90*time.Second),
+		MaxIdleConns:          orDefaultInt(cfg.MaxIdleConns, 100),
+		MaxIdleConnsPerHost:   orDefaultInt(cfg.MaxIdleConnsPerHost, 10),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+	}
+	rt := &roundTripper{
+		base:    transport,
+		retries: cfg.Retries,
+		backoff: orDefault(cfg.RetryBackoff, 100*time.Millisecond),
+		logger:  cfg.Logger,
+	}
+	return &Client{
+		Client: &http.Client{
+			Transport: rt,
+			Timeout:   orDefault(cfg.Timeout, 30*time.Second),
+		},
+		transport: transport,
+		rt:        rt,
+	}
+}
+
+// Stats returns the current counters.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests: c.rt.requests.Load(),
+		Failures: c.rt.failures.Load(),
+		Retries:  c.rt.retried.Load(),
+	}
+}
+
+// Shutdown closes idle connections. Requests still in flight are not
+// interrupted.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.transport.CloseIdleConnections()
+	return nil
+}
+
+type roundTripper struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+	logger  *slog.Logger
+
+	requests, failures, retried atomic.Int64
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	start := time.Now()
+
+	attempts := 1
+	rewindable := req.Body == nil || req.Body == http.NoBody ||
+		req.GetBody != nil
+	if isIdempotent(req.Method) && rewindable {
+		attempts += t.retries
+	}
+
+	var resp *http.Response
+	n := 0
+	err := retry.Do(req.Context(), func(ctx context.Context) error {
+		n++
+		r := req
+		if n > 1 {
+			t.retried.Add(1)
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return retry.Permanent(err)
+				}
+				r.Body = body
+			}
+		}
+		var err error
+		if resp, err = t.base.RoundTrip(r); err != nil {
+			return err
+		}
+		if n < attempts && retryableStatus(resp.StatusCode) {
+			resp.Body.Close()
+			return fmt.Errorf("server returned %s", resp.Status)
+		}
+		return nil
+	}, retry.Attempts(attempts), retry.Backoff(t.backoff, 5*time.Second),
+		retry.Jitter(0.2))
+	if err != nil {
+		resp = nil
+	}
+
+	failed := err != nil || resp.StatusCode >= 500
+	if failed {
+		t.failures.Add(1)
+	}
+	if t.logger != nil {
+		attrs := []any{"method", req.Method, "url", req.URL.Redacted(),
+			"attempts", n, "duration", time.Since(start)}
+		switch {
+		case err != nil:
+			t.logger.Warn("http request failed",
+				append(attrs, "error", err)...)
+		case failed:
+			t.logger.Warn("http request failed",
+				append(attrs, "status", resp.StatusCode)...)
+		default:
+			t.logger.Debug("http request",
+				append(attrs, "status", resp.StatusCode)...)
+		}
+	}
+	return resp, err
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusBadGateway ||
+		code == http.StatusServiceUnavailable ||
+		code == http.StatusGatewayTimeout
+}
@@ -0,0 +1,118 @@
+// Package db opens database/sql connection pools from configuration and ties
+// them into startup and shutdown.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AnuragThePathak/my-go-packages/env"
+	"github.com/AnuragThePathak/my-go-packages/retry"
+)
+
+// Config describes a connection pool. The driver must be registered by
+// importing it in the program.
+type Config struct {
+	Driver          string
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// PingAttempts is how many times Open pings the database before giving
+	// up. Defaults to 1.
+	PingAttempts int
+	// PingBackoff is the delay before the first retried ping; it doubles
+	// after every attempt. Defaults to 500ms.
+	PingBackoff time.Duration
+}
+
+// ConfigFromEnv reads a Config from DB_DRIVER, DB_DSN, DB_MAX_OPEN_CONNS,
+// DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME,
+// DB_PING_ATTEMPTS and DB_PING_BACKOFF, each preceded by prefix. DB_DRIVER and
+// DB_DSN are required, and DB_DSN is marked sensitive since it usually holds
+// the password. All invalid variables are reported together.
+func ConfigFromEnv(prefix string) (Config, error) {
+	env.Sensitive(prefix + "DB_DSN")
+	e := env.WithPrefix(prefix)
+	var cfg Config
+	var errs []error
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var err error
+	cfg.Driver, err = e.GetEnv("DB_DRIVER")
+	collect(err)
+	cfg.DSN, err = e.GetEnv("DB_DSN")
+	collect(err)
+	cfg.MaxOpenConns, err = e.GetEnvAsInt("DB_MAX_OPEN_CONNS", 0)
+	collect(err)
+	cfg.MaxIdleConns, err = e.GetEnvAsInt("DB_MAX_IDLE_CONNS", 2)
+	collect(err)
+	cfg.ConnMaxLifetime, err = e.GetEnvAsDuration("DB_CONN_MAX_LIFETIME", 0)
+	collect(err)
+	cfg.ConnMaxIdleTime, err = e.GetEnvAsDuration("DB_CONN_MAX_IDLE_TIME", 0)
+	collect(err)
+	cfg.PingAttempts, err = e.GetEnvAsInt("DB_PING_ATTEMPTS", 5)
+	collect(err)
+	cfg.PingBackoff, err = e.GetEnvAsDuration("DB_PING_BACKOFF",
+		500*time.Millisecond)
+	collect(err)
+	return cfg, errors.Join(errs...)
+}
+
+// DB is a connection pool that can report its health and be closed as part
+// of a graceful shutdown.
+type DB struct {
+	*sql.DB
+}
+
+// Open opens a pool from cfg and pings it, retrying with exponential backoff,
+// so the program does not start before its database is reachable.
+func Open(ctx context.Context, cfg Config) (*DB, error) {
+	sqlDB, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	backoff := cfg.PingBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		return sqlDB.PingContext(ctx)
+	}, retry.Attempts(cfg.PingAttempts), retry.Backoff(backoff, 30*time.Second),
+		retry.Jitter(0.2))
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return &DB{DB: sqlDB}, nil
+}
+
+// Check pings the database and is meant to back a health endpoint.
+func (db *DB) Check(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+// Shutdown closes the pool, waiting for running queries to finish or ctx to
+// be done.
+func (db *DB) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- db.Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
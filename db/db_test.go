@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/AnuragThePathak/my-go-packages/env"
+)
+
+func TestConfigFromEnvMasksDSN(t *testing.T) {
+	t.Setenv("TEST_DB_DRIVER", "postgres")
+	t.Setenv("TEST_DB_DSN", "postgres://u:hunter2@h/db")
+	cfg, err := ConfigFromEnv("TEST_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DSN != "postgres://u:hunter2@h/db" {
+		t.Errorf("DSN = %q", cfg.DSN)
+	}
+	snap, err := env.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := snap["TEST_DB_DSN"]; got != env.Masked {
+		t.Errorf("Snapshot()[TEST_DB_DSN] = %q; want %q", got, env.Masked)
+	}
+	if got := snap["TEST_DB_DRIVER"]; got != "postgres" {
+		t.Errorf("Snapshot()[TEST_DB_DRIVER] = %q; want postgres", got)
+	}
+}
@@ -0,0 +1,143 @@
+package env
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	aliasMu  sync.RWMutex
+	aliases  = map[string][]string{}
+	aliasFor = map[string]string{}
+	removeIn = map[string]string{}
+	warned   sync.Map
+
+	deprecationHook atomic.Pointer[func(oldName, varName string)]
+
+	caseInsensitive atomic.Bool
+)
+
+/*
+Alias registers old names for varName. When varName is not set, the getters
+fall back to the old names in order, and the first time one of them is used a
+deprecation warning is logged through slog. This lets a variable be renamed
+without breaking existing deployments.
+*/
+func Alias(varName string, oldNames ...string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	aliases[varName] = append(aliases[varName], oldNames...)
+	for _, old := range oldNames {
+		aliasFor[old] = varName
+	}
+}
+
+/*
+Deprecate is like Alias for a single old name but also records the version in
+which oldName stops being read, which is included in the warning.
+*/
+func Deprecate(oldName, varName, version string) {
+	Alias(varName, oldName)
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	removeIn[oldName] = version
+}
+
+/*
+OnDeprecatedRead sets a function called every time a getter falls back to an
+old name registered with Alias or Deprecate, e.g. to count the reads in a
+metric. Passing nil removes it.
+*/
+func OnDeprecatedRead(fn func(oldName, varName string)) {
+	if fn == nil {
+		deprecationHook.Store(nil)
+		return
+	}
+	deprecationHook.Store(&fn)
+}
+
+/*
+SetCaseInsensitive makes the getters fall back to a variable whose name only
+differs in case, e.g. db_url for DB_URL, when the exact name is not set. It is
+off by default.
+*/
+func SetCaseInsensitive(enabled bool) {
+	caseInsensitive.Store(enabled)
+}
+
+// isAlias reports whether name was registered as an old name.
+func isAlias(name string) bool {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	_, ok := aliasFor[name]
+	return ok
+}
+
+// lookupName reads varName from the source set with SetSource, or the process
+// environment if there is none, falling back to its aliases.
+func lookupName(varName string) (string, bool, error) {
+	if src := source.Load(); src != nil {
+		return lookupAliased((*src).Lookup, varName)
+	}
+	return lookupAliased(lookupProcess, varName)
+}
+
+// lookupAliased reads varName with look, falling back to its aliases.
+func lookupAliased(look lookupFunc, varName string) (string, bool, error) {
+	if val, ok, err := look(varName); err != nil || ok {
+		return val, ok, err
+	}
+	aliasMu.RLock()
+	oldNames := aliases[varName]
+	aliasMu.RUnlock()
+	for _, old := range oldNames {
+		val, ok, err := look(old)
+		if err != nil {
+			return "", false, err
+		}
+		if ok {
+			if _, seen := warned.LoadOrStore(old, true); !seen {
+				warnDeprecated(old, varName)
+			}
+			if fn := deprecationHook.Load(); fn != nil {
+				(*fn)(old, varName)
+			}
+			return val, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func warnDeprecated(oldName, varName string) {
+	aliasMu.RLock()
+	version, ok := removeIn[oldName]
+	aliasMu.RUnlock()
+	if ok {
+		slog.Warn("environment variable is deprecated", "name", oldName,
+			"use", varName, "removed_in", version)
+		return
+	}
+	slog.Warn("environment variable is deprecated", "name", oldName,
+		"use", varName)
+}
+
+// lookupProcess reads varName from the process environment, falling back to
+// names differing only in case if enabled.
+func lookupProcess(varName string) (string, bool, error) {
+	if val, ok := os.LookupEnv(varName); ok {
+		return val, true, nil
+	}
+	if !caseInsensitive.Load() {
+		return "", false, nil
+	}
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(name, varName) {
+			return val, true, nil
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,245 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+CachedSource wraps src so that each reference is resolved at most once per
+ttl. Failed lookups are not cached.
+*/
+func CachedSource(src SecretSource, ttl time.Duration) SecretSource {
+	return &cachedSource{src: src, ttl: ttl, entries: map[string]cachedSecret{}}
+}
+
+type cachedSecret struct {
+	val     string
+	expires time.Time
+}
+
+type cachedSource struct {
+	src     SecretSource
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+func (c *cachedSource) Resolve(ref string) (string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[ref]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.val, nil
+	}
+	val, err := c.src.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[ref] = cachedSecret{val: val, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return val, nil
+}
+
+/*
+AzureKeyVaultSource resolves references of the form "vault/secret" or
+"vault/secret/version" against the Azure Key Vault REST API, e.g.
+"myvault/db-password". When Token is empty, an access token is requested from
+the managed identity endpoint, using AZURE_CLIENT_ID to pick a user-assigned
+identity if it is set. The token is cached until shortly before it expires.
+Client defaults to a client with a 10 second timeout.
+*/
+type AzureKeyVaultSource struct {
+	Token  string
+	Client *http.Client
+}
+
+func (a AzureKeyVaultSource) Resolve(ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("key vault reference %q is not vault/secret",
+			ref)
+	}
+	client := a.Client
+	if client == nil {
+		client = secretsClient
+	}
+	token := a.Token
+	if token == "" {
+		var err error
+		if token, err = azureToken(client); err != nil {
+			return "", err
+		}
+	}
+	u := "https://" + parts[0] + ".vault.azure.net/secrets/" +
+		strings.Join(parts[1:], "/") + "?api-version=7.4"
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := fetchJSON(client, req, &body); err != nil {
+		return "", fmt.Errorf("key vault secret %s: %w", ref, err)
+	}
+	return body.Value, nil
+}
+
+func azureToken(client *http.Client) (string, error) {
+	q := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {"https://vault.azure.net"},
+	}
+	if id := os.Getenv("AZURE_CLIENT_ID"); id != "" {
+		q.Set("client_id", id)
+	}
+	u := "http://169.254.169.254/metadata/identity/oauth2/token?" + q.Encode()
+	return cachedToken(u, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Metadata", "true")
+		return req, nil
+	}, client)
+}
+
+/*
+GCPSecretManagerSource resolves references of the form "project/secret" or
+"project/secret/version" against the Google Cloud Secret Manager REST API, e.g.
+"my-project/db-password". The latest version is used unless one is given. When
+Token is empty, an access token for the default service account is requested
+from the metadata server, which GCE_METADATA_HOST can override, and cached
+until shortly before it expires. Client defaults to a client with a 10 second
+timeout.
+*/
+type GCPSecretManagerSource struct {
+	Token  string
+	Client *http.Client
+}
+
+func (g GCPSecretManagerSource) Resolve(ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("secret manager reference %q is not "+
+			"project/secret", ref)
+	}
+	version := "latest"
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+	client := g.Client
+	if client == nil {
+		client = secretsClient
+	}
+	token := g.Token
+	if token == "" {
+		var err error
+		if token, err = gcpToken(client); err != nil {
+			return "", err
+		}
+	}
+	u := "https://secretmanager.googleapis.com/v1/projects/" + parts[0] +
+		"/secrets/" + parts[1] + "/versions/" + version + ":access"
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := fetchJSON(client, req, &body); err != nil {
+		return "", fmt.Errorf("secret manager secret %s: %w", ref, err)
+	}
+	b, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func gcpToken(client *http.Client) (string, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	u := "http://" + host +
+		"/computeMetadata/v1/instance/service-accounts/default/token"
+	return cachedToken(u, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		return req, nil
+	}, client)
+}
+
+type token struct {
+	value   string
+	expires time.Time
+}
+
+var (
+	tokensMu sync.Mutex
+	tokens   = map[string]token{}
+)
+
+// cachedToken returns the access token issued by the endpoint u, requesting a
+// new one with newReq when there is none or it expires within a minute.
+func cachedToken(u string, newReq func() (*http.Request, error),
+	client *http.Client) (string, error) {
+	tokensMu.Lock()
+	t, ok := tokens[u]
+	tokensMu.Unlock()
+	if ok && time.Until(t.expires) > time.Minute {
+		return t.value, nil
+	}
+	req, err := newReq()
+	if err != nil {
+		return "", err
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		// Azure sends expires_in as a string and Google as a number.
+		ExpiresIn json.Number `json:"expires_in"`
+	}
+	if err := fetchJSON(client, req, &body); err != nil {
+		return "", fmt.Errorf("access token: %w", err)
+	}
+	secs, err := body.ExpiresIn.Int64()
+	if err != nil {
+		// Without a lifetime, use the token once without caching it.
+		return body.AccessToken, nil
+	}
+	tokensMu.Lock()
+	tokens[u] = token{value: body.AccessToken,
+		expires: time.Now().Add(time.Duration(secs) * time.Second)}
+	tokensMu.Unlock()
+	return body.AccessToken, nil
+}
+
+func fetchJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,453 @@
+// Package env reads typed configuration from environment variables and other
+// sources.
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupFunc reports the value of a variable and whether it is set.
+type lookupFunc func(varName string) (string, bool, error)
+
+// lookup reads varName from the environment, or from the file named by
+// varName_FILE, expands references to other variables if enabled and resolves
+// a secret reference in its value.
+func lookup(varName string) (string, bool, error) {
+	val, ok, fromFile, err := lookupWithFile(lookupName, varName)
+	if err != nil || !ok || fromFile {
+		return val, ok, err
+	}
+	val, err = expand(varName, val)
+	if err != nil {
+		return "", true, resolveError(varName, err)
+	}
+	val, err = resolveSecret(varName, val)
+	return val, true, err
+}
+
+// lookupWithFile reads varName with look. If it is not set, it implements the
+// convention of reading the value from the file named by the variable with a
+// _FILE suffix, e.g. DB_PASSWORD_FILE for DB_PASSWORD. A single trailing
+// newline is trimmed from the file's contents. fromFile reports whether the
+// value came from a file; such values are not expanded or resolved further.
+func lookupWithFile(look lookupFunc, varName string) (val string, ok,
+	fromFile bool, err error) {
+	val, ok, err = look(varName)
+	if err != nil {
+		return "", false, false, resolveError(varName, err)
+	}
+	if ok {
+		return val, true, false, nil
+	}
+	path, ok, err := look(varName + fileSuffix)
+	if err != nil {
+		return "", false, false, resolveError(varName, err)
+	}
+	if !ok {
+		return "", false, false, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", true, false, resolveError(varName, err)
+	}
+	val = strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(val, "\r"), true, true, nil
+}
+
+const fileSuffix = "_FILE"
+
+/*
+GetEnv takes the name of the environment variable as the first parameter. If 
+the environment variable is found, the value is returned. If the environment 
+variable is not found, the second parameter is used for a default value. If the 
+second parameter is not set, an error is returned. You may choose to provide 
+default value depending on your needs.
+*/
+func GetEnv(varName string, params ...string) (string, error) {
+	return get(lookup, varName, params, "string", "a string", parseString)
+}
+
+/*
+GetEnvAsInt takes the name of the environment variable as the first parameter. If 
+the environment variable is found and the value is of type integer, the value is 
+returned. If the environment variable is not found, the second parameter is used 
+for a default value. If the second parameter is not set, an error is returned. You 
+may choose to provide default value depending on your needs.
+*/
+func GetEnvAsInt(varName string, params ...int) (int, error) {
+	return get(lookup, varName, params, "int", "an integer", strconv.Atoi)
+}
+
+/*
+GetEnvAsInt64 takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a 64-bit 
+integer, the value is returned. If the environment variable is not found, the 
+second parameter is used for a default value. If the second parameter is not 
+set, an error is returned.
+*/
+func GetEnvAsInt64(varName string, params ...int64) (int64, error) {
+	return get(lookup, varName, params, "int64", "a 64-bit integer",
+		parseInt64)
+}
+
+/*
+GetEnvAsUint takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is an unsigned 
+integer, the value is returned. If the environment variable is not found, the 
+second parameter is used for a default value. If the second parameter is not 
+set, an error is returned.
+*/
+func GetEnvAsUint(varName string, params ...uint) (uint, error) {
+	return get(lookup, varName, params, "uint", "an unsigned integer",
+		parseUint)
+}
+
+/*
+GetEnvAsUint64 takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a 64-bit 
+unsigned integer, the value is returned. If the environment variable is not 
+found, the second parameter is used for a default value. If the second 
+parameter is not set, an error is returned.
+*/
+func GetEnvAsUint64(varName string, params ...uint64) (uint64, error) {
+	return get(lookup, varName, params, "uint64",
+		"a 64-bit unsigned integer", parseUint64)
+}
+
+/*
+GetEnvAsBool takes the name of the environment variable as the first parameter. 
+If the environment variable is found and the value is of type boolean, the value 
+is returned. If the environment variable is not found, the second parameter is 
+used for a default value. If the second parameter is not set, an error is 
+returned. You may choose to provide default value depending on your needs.
+*/
+func GetEnvAsBool(varName string, params ...bool) (bool, error) {
+	return get(lookup, varName, params, "bool", "a boolean", strconv.ParseBool)
+}
+
+/*
+GetEnvAsBoolLenient is like GetEnvAsBool but also accepts yes/no, on/off and 
+enabled/disabled, ignoring case.
+*/
+func GetEnvAsBoolLenient(varName string, params ...bool) (bool, error) {
+	return get(lookup, varName, params, "bool", "a boolean", parseBoolLenient)
+}
+
+/*
+GetEnvAsFloat64 takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a floating 
+point number, the value is returned. If the environment variable is not found, 
+the second parameter is used for a default value. If the second parameter is 
+not set, an error is returned.
+*/
+func GetEnvAsFloat64(varName string, params ...float64) (float64, error) {
+	return get(lookup, varName, params, "float64", "a float", parseFloat64)
+}
+
+/*
+GetEnvAsURL takes the name of the environment variable as the first parameter. 
+If the environment variable is found and the value is an absolute URL with a 
+host, the parsed URL is returned. If the environment variable is not found, the 
+second parameter is used for a default value. If the second parameter is not 
+set, an error is returned.
+*/
+func GetEnvAsURL(varName string, params ...*url.URL) (*url.URL, error) {
+	return get(lookup, varName, params, "url", "a URL", parseURL)
+}
+
+/*
+GetEnvAsDuration takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a duration 
+such as "300ms" or "1h30m", the value is returned. If the environment variable 
+is not found, the second parameter is used for a default value. If the second 
+parameter is not set, an error is returned.
+*/
+func GetEnvAsDuration(varName string, params ...time.Duration) (
+	time.Duration, error) {
+	return get(lookup, varName, params, "duration", "a duration",
+		time.ParseDuration)
+}
+
+/*
+GetEnvAsMap takes the name of the environment variable as the first parameter. 
+If the environment variable is found, its value is split into pairs on pairSep 
+and each pair into a key and value on kvSep, e.g. "us-east-1:10,eu-west-1:5" 
+with "," and ":". If the environment variable is not found, the fourth parameter 
+is used for a default value. If the fourth parameter is not set, an error is 
+returned.
+*/
+func GetEnvAsMap(varName, pairSep, kvSep string,
+	params ...map[string]string) (map[string]string, error) {
+	return get(lookup, varName, params, "map", "a map",
+		mapParser(pairSep, kvSep))
+}
+
+/*
+GetEnvAsIntMap is like GetEnvAsMap, but every value must be an integer.
+*/
+func GetEnvAsIntMap(varName, pairSep, kvSep string,
+	params ...map[string]int) (map[string]int, error) {
+	return get(lookup, varName, params, "map of int", "a map of integers",
+		intMapParser(pairSep, kvSep))
+}
+
+/*
+GetEnvAsTime takes the name of the environment variable as the first parameter. 
+If the environment variable is found and the value is an RFC 3339 timestamp, 
+the time is returned. If the environment variable is not found, the second 
+parameter is used for a default value. If the second parameter is not set, an 
+error is returned.
+*/
+func GetEnvAsTime(varName string, params ...time.Time) (time.Time, error) {
+	return GetEnvAsTimeWithLayout(varName, time.RFC3339, params...)
+}
+
+/*
+GetEnvAsTimeWithLayout is like GetEnvAsTime, but parses the value with layout, 
+as in time.Parse.
+*/
+func GetEnvAsTimeWithLayout(varName, layout string, params ...time.Time) (
+	time.Time, error) {
+	return get(lookup, varName, params, "time", "a time",
+		timeParser(layout))
+}
+
+/*
+GetEnvAsBytesSize takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a size such 
+as "512KB", "10MiB" or "1.5G", the number of bytes is returned. SI units (K, M, 
+G, T, P with an optional B) are powers of 1000 and IEC units (Ki, Mi, Gi, Ti, 
+Pi with an optional B) are powers of 1024; units are case-insensitive and a 
+plain number is a count of bytes. If the environment variable is not found, the 
+second parameter is used for a default value. If the second parameter is not 
+set, an error is returned.
+*/
+func GetEnvAsBytesSize(varName string, params ...int64) (int64, error) {
+	return get(lookup, varName, params, "size", "a size", parseBytesSize)
+}
+
+/*
+GetEnvAsIP takes the name of the environment variable as the first parameter. 
+If the environment variable is found and the value is an IPv4 or IPv6 address, 
+the parsed address is returned. If the environment variable is not found, the 
+second parameter is used for a default value. If the second parameter is not 
+set, an error is returned.
+*/
+func GetEnvAsIP(varName string, params ...netip.Addr) (netip.Addr, error) {
+	return get(lookup, varName, params, "ip", "an IP address",
+		netip.ParseAddr)
+}
+
+/*
+GetEnvAsCIDR takes the name of the environment variable as the first parameter. 
+If the environment variable is found and the value is a prefix in CIDR notation 
+such as "10.0.0.0/8", the parsed prefix is returned. If the environment variable 
+is not found, the second parameter is used for a default value. If the second 
+parameter is not set, an error is returned.
+*/
+func GetEnvAsCIDR(varName string, params ...netip.Prefix) (netip.Prefix,
+	error) {
+	return get(lookup, varName, params, "cidr", "a CIDR prefix",
+		netip.ParsePrefix)
+}
+
+/*
+GetEnvAsHostPort takes the name of the environment variable as the first 
+parameter. If the environment variable is found and the value is a host:port 
+pair with a numeric port, such as "db:5432", "[::1]:80" or ":8080", the value 
+is returned. If the environment variable is not found, the second parameter is 
+used for a default value. If the second parameter is not set, an error is 
+returned.
+*/
+func GetEnvAsHostPort(varName string, params ...string) (string, error) {
+	return get(lookup, varName, params, "hostport", "a host:port pair",
+		parseHostPort)
+}
+
+/*
+GetEnvAsJSON takes the name of the environment variable as the first parameter 
+and unmarshals its JSON value into target. An error naming the variable is 
+returned if the environment variable is not set or the value is not valid JSON 
+for target.
+*/
+func GetEnvAsJSON(varName string, target any) error {
+	return getJSON(lookup, varName, target)
+}
+
+// get reads varName through lookup and parses it, falling back to the
+// default in params when the variable is not set.
+func get[T any](lookup lookupFunc, varName string, params []T, typ,
+	desc string, parse func(string) (T, error)) (T, error) {
+	register(varName, typ, params)
+	var zero T
+	val, ok, err := lookup(varName)
+	if err != nil {
+		return zero, err
+	}
+	if !ok {
+		if len(params) == 0 {
+			return zero, notSetError(varName)
+		}
+		return params[0], nil
+	}
+	v, err := parse(val)
+	if err != nil {
+		return zero, parseError(varName, val, typ, desc, err)
+	}
+	return v, nil
+}
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseUint(s string) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 0)
+	return uint(n), err
+}
+
+func parseUint64(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func timeParser(layout string) func(string) (time.Time, error) {
+	return func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	}
+}
+
+func parseBoolLenient(s string) (bool, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "yes", "y", "on", "enabled", "enable":
+		return true, nil
+	case "no", "n", "off", "disabled", "disable":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute URL", s)
+	}
+	return u, nil
+}
+
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "ti": 1 << 40, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pi": 1 << 50, "pib": 1 << 50,
+}
+
+func parseBytesSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", unit)
+	}
+	size := n * mult
+	if math.IsNaN(size) || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which int64 can't hold.
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q overflows int64", s)
+	}
+	return int64(size), nil
+}
+
+func parseHostPort(s string) (string, error) {
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return "", err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid port %q", port)
+	}
+	return s, nil
+}
+
+func mapParser(pairSep, kvSep string) func(string) (map[string]string, error) {
+	return func(s string) (map[string]string, error) {
+		m := make(map[string]string)
+		for _, pair := range strings.Split(s, pairSep) {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(pair, kvSep)
+			if !ok {
+				return nil, fmt.Errorf("%q has no separator", pair)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		return m, nil
+	}
+}
+
+func intMapParser(pairSep, kvSep string) func(string) (map[string]int, error) {
+	parseMap := mapParser(pairSep, kvSep)
+	return func(s string) (map[string]int, error) {
+		m, err := parseMap(s)
+		if err != nil {
+			return nil, err
+		}
+		nums := make(map[string]int, len(m))
+		for k, v := range m {
+			if nums[k], err = strconv.Atoi(v); err != nil {
+				return nil, err
+			}
+		}
+		return nums, nil
+	}
+}
+
+func getJSON(lookup lookupFunc, varName string, target any) error {
+	register[any](varName, "json", nil)
+	val, ok, err := lookup(varName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return notSetError(varName)
+	}
+	if err := json.Unmarshal([]byte(val), target); err != nil {
+		return parseError(varName, val, "json", "JSON", err)
+	}
+	return nil
+}
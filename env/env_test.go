@@ -0,0 +1,114 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseBytesSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{" 512 ", 512},
+		{"512b", 512},
+		{"1k", 1000},
+		{"1KB", 1000},
+		{"1Ki", 1024},
+		{"1KiB", 1024},
+		{"1.5KiB", 1536},
+		{".5k", 500},
+		{"10 MB", 10_000_000},
+		{"10MiB", 10 << 20},
+		{"2g", 2_000_000_000},
+		{"2GiB", 2 << 30},
+		{"3tb", 3_000_000_000_000},
+		{"3TiB", 3 << 40},
+		{"4pb", 4_000_000_000_000_000},
+		{"4PiB", 4 << 50},
+		{"8191PiB", 8191 << 50},
+		{"9223372036854774784", 1<<63 - 1024},
+	}
+	for _, tt := range tests {
+		got, err := parseBytesSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseBytesSize(%q) = %d, %v; want %d", tt.in, got, err,
+				tt.want)
+		}
+	}
+}
+
+func TestParseBytesSizeErrors(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "invalid syntax"},
+		{"MB", "invalid syntax"},
+		{"-1", "invalid syntax"},
+		{"NaN", "invalid syntax"},
+		{"Inf", "invalid syntax"},
+		{"1.2.3", "invalid syntax"},
+		{"1e3", "unknown size unit"},
+		{"1 EB", "unknown size unit"},
+		{"1 kbit", "unknown size unit"},
+		{"8192PiB", "overflows int64"},
+		{"9223372036854775807", "overflows int64"},
+		{"10000pb", "overflows int64"},
+	}
+	for _, tt := range tests {
+		got, err := parseBytesSize(tt.in)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseBytesSize(%q) = %d, %v; want error %q", tt.in, got,
+				err, tt.want)
+		}
+	}
+}
+
+func TestGetEnvAsBytesSize(t *testing.T) {
+	t.Setenv("TEST_SIZE", "64MiB")
+	if got, err := GetEnvAsBytesSize("TEST_SIZE"); err != nil || got != 64<<20 {
+		t.Errorf("GetEnvAsBytesSize = %d, %v; want %d", got, err, 64<<20)
+	}
+	t.Setenv("TEST_SIZE", "8192PiB")
+	if _, err := GetEnvAsBytesSize("TEST_SIZE"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("GetEnvAsBytesSize error = %v; want ErrInvalid", err)
+	}
+}
+
+func TestParseBoolLenient(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"true", true},
+		{" true ", true},
+		{"TRUE", true},
+		{"1", true},
+		{"t", true},
+		{"yes", true},
+		{" Yes\n", true},
+		{"on", true},
+		{"enabled", true},
+		{"false", false},
+		{" false ", false},
+		{"0", false},
+		{"NO", false},
+		{"off ", false},
+		{"disabled", false},
+	}
+	for _, tt := range tests {
+		got, err := parseBoolLenient(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseBoolLenient(%q) = %v, %v; want %v", tt.in, got, err,
+				tt.want)
+		}
+	}
+	for _, in := range []string{"", "maybe", "2", "yess"} {
+		if _, err := parseBoolLenient(in); err == nil {
+			t.Errorf("parseBoolLenient(%q) succeeded; want an error", in)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrNotSet matches errors for variables that are not set.
+	ErrNotSet = errors.New("not set")
+	// ErrInvalid matches errors for values that can't be parsed or break a
+	// validation rule.
+	ErrInvalid = errors.New("invalid value")
+)
+
+// Kind classifies an Error.
+type Kind int
+
+const (
+	// Missing means the variable is not set and has no default.
+	Missing Kind = iota + 1
+	// ParseFailure means the value can't be parsed as the requested type.
+	ParseFailure
+	// ValidationFailure means the value breaks a validation rule.
+	ValidationFailure
+	// ResolveFailure means a secret or variable reference in the value can't
+	// be resolved.
+	ResolveFailure
+)
+
+/*
+Error describes why a variable could not be read. It matches ErrNotSet or
+ErrInvalid with errors.Is, so callers can tell an unset variable from a broken
+one. RawValue is masked for variables marked Sensitive, and the message then
+leaves out the reason a value can't be parsed, which often quotes it.
+*/
+type Error struct {
+	VarName  string
+	Kind     Kind
+	RawValue string
+	// TargetType is the type the value was parsed as, e.g. "int".
+	TargetType string
+	// Err is the underlying error, if any.
+	Err error
+
+	// desc is TargetType with an article, for the message.
+	desc string
+	// masked records that RawValue was masked.
+	masked bool
+}
+
+func (e *Error) Error() string {
+	switch e.Kind {
+	case Missing:
+		return e.VarName + " is not set"
+	case ParseFailure:
+		desc := e.desc
+		if desc == "" {
+			desc = e.TargetType
+		}
+		msg := fmt.Sprintf("%s can't be parsed as %s", e.VarName, desc)
+		// Reasons often quote the value, so they are left out when it is
+		// masked.
+		if e.Err != nil && !e.masked {
+			msg += ": " + reason(e.Err).Error()
+		}
+		return msg
+	case ValidationFailure:
+		return fmt.Sprintf("%s %v", e.VarName, e.Err)
+	case ResolveFailure:
+		return fmt.Sprintf("%s can't be resolved: %v", e.VarName, e.Err)
+	}
+	return e.VarName + " is invalid"
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotSet:
+		return e.Kind == Missing
+	case ErrInvalid:
+		return e.Kind == ParseFailure || e.Kind == ValidationFailure
+	}
+	return false
+}
+
+func notSetError(varName string) error {
+	return &Error{VarName: varName, Kind: Missing}
+}
+
+/*
+NewParseError returns a ParseFailure for val, which can't be parsed as
+targetType. It masks val if varName is marked Sensitive, so code parsing values
+read through this package reports errors like the getters do.
+*/
+func NewParseError(varName, val, targetType string, err error) *Error {
+	return parseError(varName, val, targetType, "", err)
+}
+
+/*
+NewValidationError returns a ValidationFailure for val, which breaks the rule
+described by err. It masks val if varName is marked Sensitive.
+*/
+func NewValidationError(varName, val string, err error) *Error {
+	return &Error{VarName: varName, Kind: ValidationFailure,
+		RawValue: maskIfSensitive(varName, val), Err: err}
+}
+
+func parseError(varName, val, typ, desc string, err error) *Error {
+	return &Error{VarName: varName, Kind: ParseFailure,
+		RawValue: maskIfSensitive(varName, val), TargetType: typ, Err: err,
+		desc: desc, masked: isSensitive(varName)}
+}
+
+// reason strips the function name and quoted input strconv adds to its
+// errors, which the message already identifies.
+func reason(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+func maskIfSensitive(varName, val string) string {
+	if isSensitive(varName) {
+		return Masked
+	}
+	return val
+}
+
+func resolveError(varName string, err error) error {
+	return &Error{VarName: varName, Kind: ResolveFailure, Err: err}
+}
@@ -0,0 +1,85 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorMessages(t *testing.T) {
+	t.Setenv("ERR_INT", "abc")
+	t.Setenv("ERR_BIG", "99999999999999999999")
+	t.Setenv("ERR_SIZE", "8192PiB")
+	t.Setenv("ERR_UNIT", "1 EB")
+	t.Setenv("ERR_URL", "/relative")
+	t.Setenv("ERR_JSON", "{")
+	t.Setenv("ERR_SECRET", "hunter2")
+	Sensitive("ERR_SECRET")
+
+	tests := []struct {
+		name string
+		get  func() error
+		want string
+	}{
+		{"unset", func() error {
+			_, err := GetEnvAsInt("ERR_UNSET")
+			return err
+		}, "ERR_UNSET is not set"},
+		{"int", func() error {
+			_, err := GetEnvAsInt("ERR_INT")
+			return err
+		}, "ERR_INT can't be parsed as an integer: invalid syntax"},
+		{"int range", func() error {
+			_, err := GetEnvAsInt("ERR_BIG")
+			return err
+		}, "ERR_BIG can't be parsed as an integer: value out of range"},
+		{"size overflow", func() error {
+			_, err := GetEnvAsBytesSize("ERR_SIZE")
+			return err
+		}, `ERR_SIZE can't be parsed as a size: size "8192PiB" overflows int64`},
+		{"size unit", func() error {
+			_, err := GetEnvAsBytesSize("ERR_UNIT")
+			return err
+		}, `ERR_UNIT can't be parsed as a size: unknown size unit "eb"`},
+		{"url", func() error {
+			_, err := GetEnvAsURL("ERR_URL")
+			return err
+		}, `ERR_URL can't be parsed as a URL: "/relative" is not an absolute URL`},
+		{"json", func() error {
+			var v any
+			return GetEnvAsJSON("ERR_JSON", &v)
+		}, "ERR_JSON can't be parsed as JSON: unexpected end of JSON input"},
+		{"sensitive", func() error {
+			_, err := GetEnvAsInt("ERR_SECRET")
+			return err
+		}, "ERR_SECRET can't be parsed as an integer"},
+		{"validation", func() error {
+			return NewValidationError("ERR_EMPTY", "",
+				errors.New("must not be empty"))
+		}, "ERR_EMPTY must not be empty"},
+		{"custom parse", func() error {
+			return NewParseError("ERR_SECRET", "hunter2", "level",
+				errors.New(`unknown level "hunter2"`))
+		}, "ERR_SECRET can't be parsed as level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("error = %v; want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMustPanicNamesFailure(t *testing.T) {
+	t.Setenv("ERR_MUST", "soon")
+	defer func() {
+		want := `ERR_MUST can't be parsed as a duration: time: invalid ` +
+			`duration "soon"`
+		if r := recover(); fmt.Sprint(r) != want {
+			t.Errorf("panic = %v; want %q", r, want)
+		}
+	}()
+	MustGetEnvAsDuration("ERR_MUST")
+}
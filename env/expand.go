@@ -0,0 +1,122 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+var expansion atomic.Bool
+
+/*
+SetExpansion turns expansion of ${VAR} and $VAR references in values on or off
+for all getters. It is off by default. When on, a value such as
+"postgres://user:${DB_PASS}@${DB_HOST}/app" is resolved recursively against the
+environment, and undefined or cyclic references are reported as errors.
+*/
+func SetExpansion(enabled bool) {
+	expansion.Store(enabled)
+}
+
+/*
+Expand replaces ${VAR} and $VAR references in s with the values of the
+referenced environment variables, recursively, whether or not expansion is
+enabled for the getters. A "$" not followed by a variable name is kept as is.
+Other ${...} forms, such as the shell default ${VAR:-default}, are reported as
+errors.
+*/
+func Expand(s string) (string, error) {
+	return expandString(s, nil)
+}
+
+func expand(varName, val string) (string, error) {
+	if !expansion.Load() {
+		return val, nil
+	}
+	return expandString(val, []string{varName})
+}
+
+func expandString(s string, seen []string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	if err := checkBraces(s); err != nil {
+		return "", err
+	}
+	var err error
+	out := os.Expand(s, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		if !isVarName(ref) {
+			return "$" + ref
+		}
+		if slices.Contains(seen, ref) {
+			err = fmt.Errorf("cyclic reference to %s", ref)
+			return ""
+		}
+		var val string
+		var ok, fromFile bool
+		val, ok, fromFile, err = lookupWithFile(lookupName, ref)
+		if err != nil {
+			return ""
+		}
+		if !ok {
+			if len(seen) == 0 {
+				err = notSetError(ref)
+			} else {
+				err = fmt.Errorf("%s references %s, which is not set",
+					seen[len(seen)-1], ref)
+			}
+			return ""
+		}
+		if fromFile {
+			return val
+		}
+		if val, err = expandString(val, append(seen, ref)); err != nil {
+			return ""
+		}
+		if val, err = resolveSecret(ref, val); err != nil {
+			return ""
+		}
+		return val
+	})
+	return out, err
+}
+
+// checkBraces rejects ${...} references that are not a plain variable name,
+// such as the shell default ${VAR:-default}, which os.Expand would silently
+// mangle.
+func checkBraces(s string) error {
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			return nil
+		}
+		s = s[i+2:]
+		j := strings.IndexByte(s, '}')
+		if j < 0 {
+			return errors.New("unterminated ${ reference")
+		}
+		if ref := s[:j]; !isVarName(ref) {
+			return fmt.Errorf("unsupported reference ${%s}", ref)
+		}
+		s = s[j+1:]
+	}
+}
+
+func isVarName(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for _, c := range s {
+		if c != '_' && (c < '0' || c > '9') && (c < 'a' || c > 'z') &&
+			(c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
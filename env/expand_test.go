@@ -0,0 +1,105 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("EXP_A", "a")
+	t.Setenv("EXP_B", "b")
+	t.Setenv("EXP_AB", "${EXP_A}-$EXP_B")
+	t.Setenv("EXP_NESTED", "[$EXP_AB]")
+	t.Setenv("EXP_DOLLAR", "$5")
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("p$ss${EXP_A}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EXP_FILE_FILE", secret)
+
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"$EXP_A", "a"},
+		{"${EXP_A}", "a"},
+		{"x${EXP_A}y", "xay"},
+		{"$EXP_A$EXP_B", "ab"},
+		{"$EXP_AB", "a-b"},
+		{"$EXP_NESTED", "[a-b]"},
+		{"$EXP_DOLLAR", "$5"},
+		{"$5", "$5"},
+		{"cost: 5$", "cost: 5$"},
+		{"$$", "$$"},
+		// Values read from files are taken literally.
+		{"${EXP_FILE}", "p$ss${EXP_A}"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	t.Setenv("EXP_SELF", "x$EXP_SELF")
+	t.Setenv("EXP_PING", "${EXP_PONG}")
+	t.Setenv("EXP_PONG", "${EXP_PING}")
+	t.Setenv("EXP_DANGLING", "${EXP_UNSET}")
+	t.Setenv("EXP_DEFAULT", "${EXP_UNSET:-d}")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"$EXP_SELF", "cyclic reference to EXP_SELF"},
+		{"$EXP_PING", "cyclic reference to EXP_PING"},
+		{"$EXP_DANGLING",
+			"EXP_DANGLING references EXP_UNSET, which is not set"},
+		{"$EXP_UNSET", "EXP_UNSET is not set"},
+		{"$EXP_DEFAULT", "unsupported reference ${EXP_UNSET:-d}"},
+		{"${EXP_UNSET:-d}", "unsupported reference ${EXP_UNSET:-d}"},
+		{"${EXP_UNSET:=d}", "unsupported reference ${EXP_UNSET:=d}"},
+		{"${#EXP_A}", "unsupported reference ${#EXP_A}"},
+		{"${}", "unsupported reference ${}"},
+		{"${EXP_A", "unterminated ${ reference"},
+		{"ok ${EXP_A} ${EXP_B", "unterminated ${ reference"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.in)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Expand(%q) = %q, %v; want error %q", tt.in, got, err,
+				tt.want)
+		}
+	}
+}
+
+func TestGetEnvExpansion(t *testing.T) {
+	SetExpansion(true)
+	t.Cleanup(func() { SetExpansion(false) })
+	t.Setenv("EXP_HOST", "db")
+	t.Setenv("EXP_URL", "postgres://${EXP_HOST}/app")
+	t.Setenv("EXP_LOOP", "$EXP_LOOP")
+
+	if got, err := GetEnv("EXP_URL", ""); err != nil ||
+		got != "postgres://db/app" {
+		t.Errorf("GetEnv(EXP_URL) = %q, %v; want %q", got, err,
+			"postgres://db/app")
+	}
+	_, err := GetEnv("EXP_LOOP", "")
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Kind != ResolveFailure ||
+		!strings.Contains(err.Error(), "cyclic reference to EXP_LOOP") {
+		t.Errorf("GetEnv(EXP_LOOP) error = %v; want a cyclic reference", err)
+	}
+
+	SetExpansion(false)
+	if got, err := GetEnv("EXP_URL", ""); err != nil ||
+		got != "postgres://${EXP_HOST}/app" {
+		t.Errorf("GetEnv(EXP_URL) without expansion = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+/*
+PodName returns the pod name from POD_NAME, the variable conventionally filled
+from metadata.name through the Kubernetes downward API.
+*/
+func PodName() (string, error) {
+	return GetEnv("POD_NAME")
+}
+
+/*
+PodNamespace returns the namespace from POD_NAMESPACE, falling back to the
+namespace file of the mounted service account.
+*/
+func PodNamespace() (string, error) {
+	ns, err := GetEnv("POD_NAMESPACE", "")
+	if err != nil || ns != "" {
+		return ns, err
+	}
+	b, err := os.ReadFile(namespaceFile)
+	if err != nil {
+		return "", notSetError("POD_NAMESPACE")
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+/*
+PodIP returns the pod IP from POD_IP, the variable conventionally filled from
+status.podIP through the Kubernetes downward API.
+*/
+func PodIP() (net.IP, error) {
+	val, err := GetEnv("POD_IP")
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(val)
+	if ip == nil {
+		return nil, parseError("POD_IP", val, "ip", "an IP address", nil)
+	}
+	return ip, nil
+}
+
+/*
+ServiceURL builds a URL with the given scheme from the {NAME}_SERVICE_HOST and
+{NAME}_SERVICE_PORT variables Kubernetes injects for every service in the
+namespace. The service name is upper cased and dashes are replaced with
+underscores, so "user-api" reads USER_API_SERVICE_HOST.
+*/
+func ServiceURL(service, scheme string) (*url.URL, error) {
+	prefix := strings.ReplaceAll(strings.ToUpper(service), "-", "_")
+	host, err := GetEnv(prefix + "_SERVICE_HOST")
+	if err != nil {
+		return nil, err
+	}
+	port, err := GetEnv(prefix + "_SERVICE_PORT")
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port)}, nil
+}
+
+/*
+Dir returns a Source reading a directory with one file per variable, as
+produced by mounting a ConfigMap or Secret as a volume. Files are read on every
+lookup, so updates to the mount are picked up, and a single trailing newline is
+trimmed.
+*/
+func Dir(path string) Source {
+	return SourceFunc(func(varName string) (string, bool, error) {
+		if varName == "" || strings.ContainsAny(varName, `/\`) ||
+			strings.HasPrefix(varName, ".") {
+			return "", false, nil
+		}
+		val, err := FileSource{}.Resolve(filepath.Join(path, varName))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return val, true, nil
+	})
+}
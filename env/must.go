@@ -0,0 +1,46 @@
+package env
+
+import "time"
+
+/*
+The Must variants below behave like their counterparts but panic instead of
+returning an error. They are meant for configuration read in main, where a
+missing or invalid variable is always fatal. The panic message names the
+variable and the failure.
+*/
+
+// MustGetEnv is like GetEnv but panics on error.
+func MustGetEnv(varName string, params ...string) string {
+	val, err := GetEnv(varName, params...)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustGetEnvAsInt is like GetEnvAsInt but panics on error.
+func MustGetEnvAsInt(varName string, params ...int) int {
+	num, err := GetEnvAsInt(varName, params...)
+	if err != nil {
+		panic(err)
+	}
+	return num
+}
+
+// MustGetEnvAsBool is like GetEnvAsBool but panics on error.
+func MustGetEnvAsBool(varName string, params ...bool) bool {
+	b, err := GetEnvAsBool(varName, params...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// MustGetEnvAsDuration is like GetEnvAsDuration but panics on error.
+func MustGetEnvAsDuration(varName string, params ...time.Duration) time.Duration {
+	d, err := GetEnvAsDuration(varName, params...)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
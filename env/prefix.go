@@ -0,0 +1,171 @@
+package env
+
+import (
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+Prefixed reads environment variables under a common prefix, so several
+components of the same binary can keep their configuration in separate
+namespaces. Variable names passed to its methods are given without the prefix.
+*/
+type Prefixed struct {
+	prefix string
+}
+
+// WithPrefix returns a reader whose getters prepend prefix to every name.
+func WithPrefix(prefix string) Prefixed {
+	return Prefixed{prefix: prefix}
+}
+
+// Prefix returns the prefix prepended by p.
+func (p Prefixed) Prefix() string {
+	return p.prefix
+}
+
+// GetEnv is like the package level GetEnv with the prefix prepended.
+func (p Prefixed) GetEnv(varName string, params ...string) (string, error) {
+	return GetEnv(p.prefix+varName, params...)
+}
+
+// GetEnvAsInt is like the package level GetEnvAsInt with the prefix prepended.
+func (p Prefixed) GetEnvAsInt(varName string, params ...int) (int, error) {
+	return GetEnvAsInt(p.prefix+varName, params...)
+}
+
+// GetEnvAsInt64 is like the package level GetEnvAsInt64 with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsInt64(varName string, params ...int64) (int64,
+	error) {
+	return GetEnvAsInt64(p.prefix+varName, params...)
+}
+
+// GetEnvAsUint is like the package level GetEnvAsUint with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsUint(varName string, params ...uint) (uint, error) {
+	return GetEnvAsUint(p.prefix+varName, params...)
+}
+
+// GetEnvAsUint64 is like the package level GetEnvAsUint64 with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsUint64(varName string, params ...uint64) (uint64,
+	error) {
+	return GetEnvAsUint64(p.prefix+varName, params...)
+}
+
+// GetEnvAsBool is like the package level GetEnvAsBool with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsBool(varName string, params ...bool) (bool, error) {
+	return GetEnvAsBool(p.prefix+varName, params...)
+}
+
+// GetEnvAsBoolLenient is like the package level GetEnvAsBoolLenient with the
+// prefix prepended.
+func (p Prefixed) GetEnvAsBoolLenient(varName string, params ...bool) (bool,
+	error) {
+	return GetEnvAsBoolLenient(p.prefix+varName, params...)
+}
+
+// GetEnvAsFloat64 is like the package level GetEnvAsFloat64 with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsFloat64(varName string, params ...float64) (
+	float64, error) {
+	return GetEnvAsFloat64(p.prefix+varName, params...)
+}
+
+// GetEnvAsURL is like the package level GetEnvAsURL with the prefix prepended.
+func (p Prefixed) GetEnvAsURL(varName string, params ...*url.URL) (
+	*url.URL, error) {
+	return GetEnvAsURL(p.prefix+varName, params...)
+}
+
+// GetEnvAsDuration is like the package level GetEnvAsDuration with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsDuration(varName string, params ...time.Duration) (
+	time.Duration, error) {
+	return GetEnvAsDuration(p.prefix+varName, params...)
+}
+
+// GetEnvAsTime is like the package level GetEnvAsTime with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsTime(varName string, params ...time.Time) (
+	time.Time, error) {
+	return GetEnvAsTime(p.prefix+varName, params...)
+}
+
+// GetEnvAsTimeWithLayout is like the package level GetEnvAsTimeWithLayout with
+// the prefix prepended.
+func (p Prefixed) GetEnvAsTimeWithLayout(varName, layout string,
+	params ...time.Time) (time.Time, error) {
+	return GetEnvAsTimeWithLayout(p.prefix+varName, layout, params...)
+}
+
+// GetEnvAsBytesSize is like the package level GetEnvAsBytesSize with the
+// prefix prepended.
+func (p Prefixed) GetEnvAsBytesSize(varName string, params ...int64) (int64,
+	error) {
+	return GetEnvAsBytesSize(p.prefix+varName, params...)
+}
+
+// GetEnvAsIP is like the package level GetEnvAsIP with the prefix prepended.
+func (p Prefixed) GetEnvAsIP(varName string, params ...netip.Addr) (netip.Addr,
+	error) {
+	return GetEnvAsIP(p.prefix+varName, params...)
+}
+
+// GetEnvAsCIDR is like the package level GetEnvAsCIDR with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsCIDR(varName string, params ...netip.Prefix) (
+	netip.Prefix, error) {
+	return GetEnvAsCIDR(p.prefix+varName, params...)
+}
+
+// GetEnvAsHostPort is like the package level GetEnvAsHostPort with the prefix
+// prepended.
+func (p Prefixed) GetEnvAsHostPort(varName string, params ...string) (string,
+	error) {
+	return GetEnvAsHostPort(p.prefix+varName, params...)
+}
+
+// Require is like the package level Require with the prefix prepended.
+func (p Prefixed) Require(varNames ...string) error {
+	names := make([]string, len(varNames))
+	for i, name := range varNames {
+		names[i] = p.prefix + name
+	}
+	return Require(names...)
+}
+
+/*
+All returns every variable under the prefix, keyed by its name without the
+prefix. Names are listed from the process environment, since a Source can't be
+enumerated, but values are read like in the getters, including from the source
+set with SetSource. Names that source does not have are left out.
+*/
+func (p Prefixed) All() (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, p.prefix) {
+			continue
+		}
+		val, ok, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		vars[strings.TrimPrefix(name, p.prefix)] = val
+	}
+	return vars, nil
+}
+
+// CheckUnknown is like the package level CheckUnknown for the prefix.
+func (p Prefixed) CheckUnknown() error {
+	return CheckUnknown(p.prefix)
+}
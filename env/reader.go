@@ -0,0 +1,122 @@
+package env
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+Reader reads many variables and collects their errors, so a service can report
+every misconfiguration at startup instead of only the first:
+
+	c := env.NewReader()
+	port := c.Int("PORT", 8080)
+	dsn := c.String("DB_DSN")
+	if err := c.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+Each method is like the getter of the same type. On error it returns the zero
+value and records the error.
+*/
+type Reader struct {
+	lookup lookupFunc
+	errs   []error
+}
+
+// NewReader returns a Reader over the process environment.
+func NewReader() *Reader {
+	return &Reader{lookup: lookup}
+}
+
+// NewReader returns a Reader over r's sources.
+func (r *Resolver) NewReader() *Reader {
+	return &Reader{lookup: r.lookup}
+}
+
+// Err returns the errors recorded so far joined, or nil if there were none.
+func (c *Reader) Err() error {
+	return errors.Join(c.errs...)
+}
+
+func read[T any](c *Reader, varName string, params []T, typ, desc string,
+	parse func(string) (T, error)) T {
+	v, err := get(c.lookup, varName, params, typ, desc, parse)
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+	return v
+}
+
+// String reads varName like GetEnv.
+func (c *Reader) String(varName string, params ...string) string {
+	return read(c, varName, params, "string", "a string", parseString)
+}
+
+// Int reads varName like GetEnvAsInt.
+func (c *Reader) Int(varName string, params ...int) int {
+	return read(c, varName, params, "int", "an integer", strconv.Atoi)
+}
+
+// Int64 reads varName like GetEnvAsInt64.
+func (c *Reader) Int64(varName string, params ...int64) int64 {
+	return read(c, varName, params, "int64", "a 64-bit integer", parseInt64)
+}
+
+// Uint reads varName like GetEnvAsUint.
+func (c *Reader) Uint(varName string, params ...uint) uint {
+	return read(c, varName, params, "uint", "an unsigned integer", parseUint)
+}
+
+// Uint64 reads varName like GetEnvAsUint64.
+func (c *Reader) Uint64(varName string, params ...uint64) uint64 {
+	return read(c, varName, params, "uint64", "a 64-bit unsigned integer",
+		parseUint64)
+}
+
+// Bool reads varName like GetEnvAsBool.
+func (c *Reader) Bool(varName string, params ...bool) bool {
+	return read(c, varName, params, "bool", "a boolean", strconv.ParseBool)
+}
+
+// Float64 reads varName like GetEnvAsFloat64.
+func (c *Reader) Float64(varName string, params ...float64) float64 {
+	return read(c, varName, params, "float64", "a float", parseFloat64)
+}
+
+// URL reads varName like GetEnvAsURL.
+func (c *Reader) URL(varName string, params ...*url.URL) *url.URL {
+	return read(c, varName, params, "url", "a URL", parseURL)
+}
+
+// Duration reads varName like GetEnvAsDuration.
+func (c *Reader) Duration(varName string,
+	params ...time.Duration) time.Duration {
+	return read(c, varName, params, "duration", "a duration",
+		time.ParseDuration)
+}
+
+// BytesSize reads varName like GetEnvAsBytesSize.
+func (c *Reader) BytesSize(varName string, params ...int64) int64 {
+	return read(c, varName, params, "size", "a size", parseBytesSize)
+}
+
+// IP reads varName like GetEnvAsIP.
+func (c *Reader) IP(varName string, params ...netip.Addr) netip.Addr {
+	return read(c, varName, params, "ip", "an IP address", netip.ParseAddr)
+}
+
+// CIDR reads varName like GetEnvAsCIDR.
+func (c *Reader) CIDR(varName string, params ...netip.Prefix) netip.Prefix {
+	return read(c, varName, params, "cidr", "a CIDR prefix",
+		netip.ParsePrefix)
+}
+
+// HostPort reads varName like GetEnvAsHostPort.
+func (c *Reader) HostPort(varName string, params ...string) string {
+	return read(c, varName, params, "hostport", "a host:port pair",
+		parseHostPort)
+}
@@ -0,0 +1,122 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+/*
+Var describes an environment variable the program reads. Every getter records
+the variables it is asked for, so Describe can list the full configuration
+schema of a binary, e.g. for a --print-config flag.
+*/
+type Var struct {
+	Name string
+	Type string
+	// Default is the formatted default value, if one was given. It is
+	// masked for variables marked Sensitive.
+	Default    string
+	HasDefault bool
+	// Required is true if the variable was read without a default.
+	Required    bool
+	Description string
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Var{}
+)
+
+func register[T any](varName, typ string, params []T) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	v := registry[varName]
+	if v == nil {
+		v = &Var{Name: varName}
+		registry[varName] = v
+	}
+	if typ != "" {
+		v.Type = typ
+	}
+	if len(params) == 0 {
+		v.Required = true
+		return
+	}
+	v.Default = fmt.Sprint(params[0])
+	v.HasDefault = true
+}
+
+// Document attaches a human readable description to varName.
+func Document(varName, description string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	v := registry[varName]
+	if v == nil {
+		v = &Var{Name: varName}
+		registry[varName] = v
+	}
+	v.Description = description
+}
+
+// Describe returns every variable read or documented so far, sorted by name.
+func Describe() []Var {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	vars := make([]Var, 0, len(registry))
+	for _, v := range registry {
+		desc := *v
+		if desc.HasDefault {
+			desc.Default = maskIfSensitive(desc.Name, desc.Default)
+		}
+		vars = append(vars, desc)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// PrintUsage writes the variables returned by Describe to w as a table.
+func PrintUsage(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, v := range Describe() {
+		def := "-"
+		if v.HasDefault {
+			def = fmt.Sprintf("%q", v.Default)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", v.Name, v.Type, def,
+			v.Required, v.Description)
+	}
+	return tw.Flush()
+}
+
+/*
+Unknown returns the names of environment variables starting with prefix that
+have not been read or documented, sorted by name. NAME_FILE counts as read
+when NAME was read. Call it after loading the configuration to catch typos
+such as MYAPP_PROT.
+*/
+func Unknown(prefix string) []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	var names []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, ok := registry[name]; ok || isAlias(name) {
+			continue
+		}
+		if _, ok := registry[strings.TrimSuffix(name, fileSuffix)]; ok {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDescribeMasksSensitiveDefaults(t *testing.T) {
+	Sensitive("REG_TOKEN")
+	if _, err := GetEnv("REG_TOKEN", "dev-token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetEnv("REG_REGION", "eu-west-1"); err != nil {
+		t.Fatal(err)
+	}
+	defaults := make(map[string]string)
+	for _, v := range Describe() {
+		defaults[v.Name] = v.Default
+	}
+	if got := defaults["REG_TOKEN"]; got != Masked {
+		t.Errorf("REG_TOKEN default = %q; want %q", got, Masked)
+	}
+	if got := defaults["REG_REGION"]; got != "eu-west-1" {
+		t.Errorf("REG_REGION default = %q; want %q", got, "eu-west-1")
+	}
+	var buf bytes.Buffer
+	if err := PrintUsage(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "dev-token") {
+		t.Errorf("PrintUsage shows a sensitive default:\n%s", buf.String())
+	}
+}
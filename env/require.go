@@ -0,0 +1,23 @@
+package env
+
+import "errors"
+
+/*
+Require checks that every environment variable in varNames is set, directly or
+through a NAME_FILE variable naming a readable file. Instead of failing on the
+first missing variable, it returns the errors for all of them joined, so a
+service can report its whole misconfiguration at startup.
+*/
+func Require(varNames ...string) error {
+	var errs []error
+	for _, name := range varNames {
+		register[string](name, "", nil)
+		_, ok, _, err := lookupWithFile(lookupName, name)
+		if err != nil {
+			errs = append(errs, err)
+		} else if !ok {
+			errs = append(errs, notSetError(name))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,180 @@
+package env
+
+import (
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+Resolver merges several sources into one configuration. A variable is read
+from the first source that has it, so sources are given in order of
+precedence. The usual layering of flags over environment over a config file
+over defaults is
+
+	r := NewResolver(Flags(flag.CommandLine), ProcessEnv(), file, Map(defaults))
+
+Secret references and NAME_FILE variables are resolved like in the package
+level getters, and reads are recorded for Describe.
+*/
+type Resolver struct {
+	sources []Source
+}
+
+// NewResolver returns a Resolver reading from sources, highest precedence
+// first.
+func NewResolver(sources ...Source) *Resolver {
+	return &Resolver{sources: sources}
+}
+
+// Lookup returns the raw value of varName from the first source that has it.
+func (r *Resolver) Lookup(varName string) (string, bool, error) {
+	for _, src := range r.sources {
+		val, ok, err := src.Lookup(varName)
+		if err != nil || ok {
+			return val, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+func (r *Resolver) lookup(varName string) (string, bool, error) {
+	val, ok, fromFile, err := lookupWithFile(r.Lookup, varName)
+	if err != nil || !ok || fromFile {
+		return val, ok, err
+	}
+	val, err = resolveSecret(varName, val)
+	return val, true, err
+}
+
+// GetEnv is like the package level GetEnv but reads from r.
+func (r *Resolver) GetEnv(varName string, params ...string) (string, error) {
+	return get(r.lookup, varName, params, "string", "a string", parseString)
+}
+
+// GetEnvAsInt is like the package level GetEnvAsInt but reads from r.
+func (r *Resolver) GetEnvAsInt(varName string, params ...int) (int, error) {
+	return get(r.lookup, varName, params, "int", "an integer", strconv.Atoi)
+}
+
+// GetEnvAsInt64 is like the package level GetEnvAsInt64 but reads from r.
+func (r *Resolver) GetEnvAsInt64(varName string, params ...int64) (int64,
+	error) {
+	return get(r.lookup, varName, params, "int64", "a 64-bit integer",
+		parseInt64)
+}
+
+// GetEnvAsUint is like the package level GetEnvAsUint but reads from r.
+func (r *Resolver) GetEnvAsUint(varName string, params ...uint) (uint, error) {
+	return get(r.lookup, varName, params, "uint", "an unsigned integer",
+		parseUint)
+}
+
+// GetEnvAsUint64 is like the package level GetEnvAsUint64 but reads from r.
+func (r *Resolver) GetEnvAsUint64(varName string, params ...uint64) (uint64,
+	error) {
+	return get(r.lookup, varName, params, "uint64",
+		"a 64-bit unsigned integer", parseUint64)
+}
+
+// GetEnvAsBool is like the package level GetEnvAsBool but reads from r.
+func (r *Resolver) GetEnvAsBool(varName string, params ...bool) (bool, error) {
+	return get(r.lookup, varName, params, "bool", "a boolean",
+		strconv.ParseBool)
+}
+
+// GetEnvAsBoolLenient is like the package level GetEnvAsBoolLenient but reads
+// from r.
+func (r *Resolver) GetEnvAsBoolLenient(varName string, params ...bool) (bool,
+	error) {
+	return get(r.lookup, varName, params, "bool", "a boolean",
+		parseBoolLenient)
+}
+
+// GetEnvAsFloat64 is like the package level GetEnvAsFloat64 but reads from r.
+func (r *Resolver) GetEnvAsFloat64(varName string, params ...float64) (
+	float64, error) {
+	return get(r.lookup, varName, params, "float64", "a float", parseFloat64)
+}
+
+// GetEnvAsURL is like the package level GetEnvAsURL but reads from r.
+func (r *Resolver) GetEnvAsURL(varName string, params ...*url.URL) (
+	*url.URL, error) {
+	return get(r.lookup, varName, params, "url", "a URL", parseURL)
+}
+
+// GetEnvAsDuration is like the package level GetEnvAsDuration but reads from
+// r.
+func (r *Resolver) GetEnvAsDuration(varName string,
+	params ...time.Duration) (time.Duration, error) {
+	return get(r.lookup, varName, params, "duration", "a duration",
+		time.ParseDuration)
+}
+
+// GetEnvAsTime is like the package level GetEnvAsTime but reads from r.
+func (r *Resolver) GetEnvAsTime(varName string, params ...time.Time) (
+	time.Time, error) {
+	return r.GetEnvAsTimeWithLayout(varName, time.RFC3339, params...)
+}
+
+// GetEnvAsTimeWithLayout is like the package level GetEnvAsTimeWithLayout but
+// reads from r.
+func (r *Resolver) GetEnvAsTimeWithLayout(varName, layout string,
+	params ...time.Time) (time.Time, error) {
+	return get(r.lookup, varName, params, "time", "a time",
+		timeParser(layout))
+}
+
+// GetEnvAsBytesSize is like the package level GetEnvAsBytesSize but reads
+// from r.
+func (r *Resolver) GetEnvAsBytesSize(varName string, params ...int64) (int64,
+	error) {
+	return get(r.lookup, varName, params, "size", "a size", parseBytesSize)
+}
+
+// GetEnvAsIP is like the package level GetEnvAsIP but reads from r.
+func (r *Resolver) GetEnvAsIP(varName string, params ...netip.Addr) (
+	netip.Addr, error) {
+	return get(r.lookup, varName, params, "ip", "an IP address",
+		netip.ParseAddr)
+}
+
+// GetEnvAsCIDR is like the package level GetEnvAsCIDR but reads from r.
+func (r *Resolver) GetEnvAsCIDR(varName string, params ...netip.Prefix) (
+	netip.Prefix, error) {
+	return get(r.lookup, varName, params, "cidr", "a CIDR prefix",
+		netip.ParsePrefix)
+}
+
+// GetEnvAsHostPort is like the package level GetEnvAsHostPort but reads from
+// r.
+func (r *Resolver) GetEnvAsHostPort(varName string, params ...string) (string,
+	error) {
+	return get(r.lookup, varName, params, "hostport", "a host:port pair",
+		parseHostPort)
+}
+
+// GetEnvAsMap is like the package level GetEnvAsMap but reads from r.
+func (r *Resolver) GetEnvAsMap(varName, pairSep, kvSep string,
+	params ...map[string]string) (map[string]string, error) {
+	return get(r.lookup, varName, params, "map", "a map",
+		mapParser(pairSep, kvSep))
+}
+
+// GetEnvAsIntMap is like the package level GetEnvAsIntMap but reads from r.
+func (r *Resolver) GetEnvAsIntMap(varName, pairSep, kvSep string,
+	params ...map[string]int) (map[string]int, error) {
+	return get(r.lookup, varName, params, "map of int", "a map of integers",
+		intMapParser(pairSep, kvSep))
+}
+
+// GetEnvAsJSON is like the package level GetEnvAsJSON but reads from r.
+func (r *Resolver) GetEnvAsJSON(varName string, target any) error {
+	return getJSON(r.lookup, varName, target)
+}
+
+// Validate is like the package level Validate but reads from r.
+func (r *Resolver) Validate(varName string, rules ...Rule) error {
+	return validate(r.lookup, varName, rules)
+}
@@ -0,0 +1,150 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+SecretSource resolves a secret reference to its value. A reference is the part
+of an environment variable's value after the scheme prefix, e.g. for
+"file:/run/secrets/db_password" the file source receives
+"/run/secrets/db_password".
+*/
+type SecretSource interface {
+	Resolve(ref string) (string, error)
+}
+
+var (
+	secretsMu     sync.RWMutex
+	secretSources = map[string]SecretSource{}
+)
+
+/*
+RegisterSecretSource makes src responsible for values starting with
+"scheme:". Once registered, GetEnv and the typed getters transparently return
+the resolved secret instead of the reference. No sources are registered by
+default, so values are never interpreted unless you opt in.
+*/
+func RegisterSecretSource(scheme string, src SecretSource) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if src == nil {
+		delete(secretSources, scheme)
+		return
+	}
+	secretSources[scheme] = src
+}
+
+func resolveSecret(varName, val string) (string, error) {
+	scheme, ref, ok := strings.Cut(val, ":")
+	if !ok {
+		return val, nil
+	}
+	secretsMu.RLock()
+	src := secretSources[scheme]
+	secretsMu.RUnlock()
+	if src == nil {
+		return val, nil
+	}
+	secret, err := src.Resolve(ref)
+	if err != nil {
+		return "", resolveError(varName, err)
+	}
+	return secret, nil
+}
+
+/*
+FileSource resolves references to the contents of a file, which is how Docker
+and Kubernetes mount secrets. A single trailing newline is trimmed.
+*/
+type FileSource struct{}
+
+func (FileSource) Resolve(ref string) (string, error) {
+	b, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// secretsClient is used when a source has no Client, so that an unreachable
+// server or metadata endpoint fails a getter quickly instead of hanging.
+var secretsClient = &http.Client{Timeout: 10 * time.Second}
+
+/*
+VaultSource resolves references of the form "path#key" against the HashiCorp
+Vault HTTP API, e.g. "secret/data/db#password". Both KV version 1 and 2 engines
+are supported. Addr and Token default to VAULT_ADDR and VAULT_TOKEN, and Client
+defaults to a client with a 10 second timeout.
+*/
+type VaultSource struct {
+	Addr   string
+	Token  string
+	Client *http.Client
+}
+
+func (v VaultSource) Resolve(ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || key == "" {
+		return "", fmt.Errorf("vault reference %q has no key", ref)
+	}
+	addr, token := v.Addr, v.Token
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if addr == "" {
+		return "", fmt.Errorf("vault address is not set")
+	}
+	u := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	client := v.Client
+	if client == nil {
+		client = secretsClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	data := body.Data
+	// KV version 2 nests the secret under data.data.
+	if nested, ok := data["data"]; ok {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			data = inner
+		}
+	}
+	raw, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %s", path, key)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return string(raw), nil
+	}
+	return s, nil
+}
@@ -0,0 +1,83 @@
+package env
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Masked replaces the values of sensitive variables in snapshots.
+const Masked = "******"
+
+var (
+	sensitiveMu sync.RWMutex
+	sensitive   = map[string]bool{}
+)
+
+// Sensitive marks variables whose values must never appear in snapshots.
+func Sensitive(varNames ...string) {
+	sensitiveMu.Lock()
+	defer sensitiveMu.Unlock()
+	for _, name := range varNames {
+		sensitive[name] = true
+	}
+}
+
+func isSensitive(varName string) bool {
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+	return sensitive[varName]
+}
+
+/*
+Snapshot returns the raw value of every variable read or documented so far, as
+listed by Describe, so the result is safe to log. Values are not expanded and
+secret references such as "vault:secret/db#password" are kept as they are.
+Sensitive variables and variables read from a NAME_FILE file are replaced by
+Masked. Unset variables are left out. Variables that can't be read are left
+out too and their errors are returned joined.
+*/
+func Snapshot() (map[string]string, error) {
+	snap := make(map[string]string)
+	var errs []error
+	for _, v := range Describe() {
+		val, ok, fromFile, err := lookupWithFile(lookupName, v.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if fromFile || isSensitive(v.Name) {
+			val = Masked
+		}
+		snap[v.Name] = val
+	}
+	return snap, errors.Join(errs...)
+}
+
+/*
+Diff returns the variables that differ between two snapshots, sorted by name.
+Since sensitive values are masked, a change of a sensitive value is only
+reported when the variable is set or unset.
+*/
+func Diff(a, b map[string]string) []Change {
+	var changes []Change
+	for name, old := range a {
+		val, ok := b[name]
+		if !ok || val != old {
+			changes = append(changes, Change{Name: name, Old: old, New: val,
+				Set: ok})
+		}
+	}
+	for name, val := range b {
+		if _, ok := a[name]; !ok {
+			changes = append(changes, Change{Name: name, New: val, Set: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
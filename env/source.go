@@ -0,0 +1,171 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Source provides values for variables by name.
+type Source interface {
+	Lookup(varName string) (string, bool, error)
+}
+
+// SourceFunc adapts an ordinary function to a Source.
+type SourceFunc func(varName string) (string, bool, error)
+
+func (f SourceFunc) Lookup(varName string) (string, bool, error) {
+	return f(varName)
+}
+
+// ProcessEnv returns a Source reading the environment of the process, with
+// aliases and case-insensitive lookups applied like in the getters. It ignores
+// SetSource, so it can be part of the source passed to it.
+func ProcessEnv() Source {
+	return SourceFunc(func(varName string) (string, bool, error) {
+		return lookupAliased(lookupProcess, varName)
+	})
+}
+
+var source atomic.Pointer[Source]
+
+/*
+SetSource makes the package level getters read from src instead of the process
+environment, e.g. a Resolver layering a .env file under ProcessEnv:
+
+	dotEnv, err := env.DotEnv(".env")
+	if err != nil {
+		return err
+	}
+	env.SetSource(env.NewResolver(env.ProcessEnv(), dotEnv))
+
+Aliases, expansion, secret references and the NAME_FILE convention still
+apply on top of src. Passing nil restores the process environment.
+*/
+func SetSource(src Source) {
+	if src == nil {
+		source.Store(nil)
+		return
+	}
+	source.Store(&src)
+}
+
+// Map returns a Source reading from m, e.g. for hard-coded defaults.
+func Map(m map[string]string) Source {
+	return SourceFunc(func(varName string) (string, bool, error) {
+		val, ok := m[varName]
+		return val, ok, nil
+	})
+}
+
+/*
+Flags returns a Source reading the flags in fs that were set on the command
+line. Variable names are mapped to flag names by lower casing them and replacing
+underscores with dashes, so DB_URL is read from -db-url. Flags left at their
+default are treated as unset, so lower precedence sources can still provide a
+value.
+*/
+func Flags(fs *flag.FlagSet) Source {
+	return SourceFunc(func(varName string) (string, bool, error) {
+		name := strings.ReplaceAll(strings.ToLower(varName), "_", "-")
+		var val string
+		var ok bool
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == name {
+				val, ok = f.Value.String(), true
+			}
+		})
+		return val, ok, nil
+	})
+}
+
+/*
+DotEnv reads a file of KEY=VALUE lines, as used by .env files, and returns it as
+a Source. Blank lines, lines starting with # and an "export " prefix are
+ignored. Values may be wrapped in single quotes, taken literally, or double
+quotes, which support Go escape sequences. A # preceded by a space starts a
+comment, except inside quotes.
+*/
+func DotEnv(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: missing =", path, n)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: missing name", path, n)
+		}
+		val, err = parseDotEnvValue(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		vars[key] = val
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return Map(vars), nil
+}
+
+// parseDotEnvValue unquotes a value from a .env line and strips a trailing
+// comment.
+func parseDotEnvValue(val string) (string, error) {
+	if val == "" {
+		return "", nil
+	}
+	switch val[0] {
+	case '\'':
+		end := strings.IndexByte(val[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated quoted value")
+		}
+		return val[1 : end+1], checkTrailing(val[end+2:])
+	case '"':
+		for i := 1; i < len(val); i++ {
+			switch val[i] {
+			case '\\':
+				i++
+			case '"':
+				s, err := strconv.Unquote(val[:i+1])
+				if err != nil {
+					return "", errors.New("invalid quoted value")
+				}
+				return s, checkTrailing(val[i+1:])
+			}
+		}
+		return "", errors.New("unterminated quoted value")
+	}
+	if i := strings.Index(val, " #"); i >= 0 {
+		val = strings.TrimSpace(val[:i])
+	}
+	return val, nil
+}
+
+// checkTrailing accepts what follows a quoted value if it is blank or a
+// comment.
+func checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return errors.New("unexpected text after quoted value")
+	}
+	return nil
+}
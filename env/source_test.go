@@ -0,0 +1,72 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDotEnv(t *testing.T) {
+	tests := []struct {
+		name, line, want string
+	}{
+		{"plain", `KEY=value`, "value"},
+		{"spaces around =", `KEY = value`, "value"},
+		{"empty", `KEY=`, ""},
+		{"export prefix", `export KEY=value`, "value"},
+		{"inline comment", `KEY=value # comment`, "value"},
+		{"hash without space", `KEY=a#b`, "a#b"},
+		{"double quotes", `KEY="a b"`, "a b"},
+		{"double quote escapes", `KEY="a\nb\t\"c\""`, "a\nb\t\"c\""},
+		{"double quotes with comment", `KEY="a # b" # comment`, "a # b"},
+		{"single quotes", `KEY='a b'`, "a b"},
+		{"single quotes are literal", `KEY='a\nb ${X}'`, `a\nb ${X}`},
+		{"single quotes with comment", `KEY='a' # comment`, "a"},
+		{"equals in value", `KEY=a=b`, "a=b"},
+		{"crlf", "KEY=value\r", "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := DotEnv(writeDotEnv(t, "# header\n\n"+tt.line+"\n"))
+			if err != nil {
+				t.Fatalf("DotEnv: %v", err)
+			}
+			val, ok, err := src.Lookup("KEY")
+			if err != nil || !ok || val != tt.want {
+				t.Errorf("Lookup(KEY) = %q, %v, %v; want %q, true, nil", val,
+					ok, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotEnvErrors(t *testing.T) {
+	tests := []struct {
+		name, line, want string
+	}{
+		{"missing =", `KEY`, ":2: missing ="},
+		{"missing name", `=value`, ":2: missing name"},
+		{"bad escape", `KEY="\q"`, ":2: invalid quoted value"},
+		{"unterminated double", `KEY="abc`, ":2: unterminated quoted value"},
+		{"unterminated single", `KEY='abc`, ":2: unterminated quoted value"},
+		{"text after quotes", `KEY="a" b`, ":2: unexpected text after quoted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DotEnv(writeDotEnv(t, "A=1\n"+tt.line+"\n"))
+			if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+				t.Errorf("DotEnv error = %v; want suffix %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func writeDotEnv(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
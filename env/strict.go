@@ -0,0 +1,80 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+/*
+CheckUnknown returns an error for every variable reported by Unknown(prefix),
+joined, or nil if there are none. Call it once the configuration is loaded to
+fail startup on typos such as MYAPP_PROT=8080, which would otherwise be ignored
+while the default port is used. The error names the closest known variable
+when there is one.
+*/
+func CheckUnknown(prefix string) error {
+	var errs []error
+	for _, name := range Unknown(prefix) {
+		if near := closestKnown(name); near != "" {
+			errs = append(errs, fmt.Errorf("%s is set but never read, "+
+				"did you mean %s?", name, near))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s is set but never read", name))
+	}
+	return errors.Join(errs...)
+}
+
+/*
+WarnUnknown is like CheckUnknown but logs a warning through slog for each
+variable instead of failing.
+*/
+func WarnUnknown(prefix string) {
+	for _, name := range Unknown(prefix) {
+		if near := closestKnown(name); near != "" {
+			slog.Warn("environment variable is never read", "name", name,
+				"did_you_mean", near)
+			continue
+		}
+		slog.Warn("environment variable is never read", "name", name)
+	}
+}
+
+// closestKnown returns the recorded variable nearest to name by edit
+// distance, if it is at most two edits away.
+func closestKnown(name string) string {
+	best, bestDist := "", 3
+	for _, v := range Describe() {
+		if d := editDistance(name, v.Name); d < bestDist {
+			best, bestDist = v.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b,
+// counting a swap of adjacent characters as one edit.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
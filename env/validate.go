@@ -0,0 +1,100 @@
+package env
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule checks the value of a variable and describes what is wrong with it.
+type Rule func(val string) error
+
+// Min requires a number greater than or equal to n.
+func Min(n float64) Rule {
+	return func(val string) error {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		if f < n {
+			return fmt.Errorf("must be at least %v", n)
+		}
+		return nil
+	}
+}
+
+// Max requires a number less than or equal to n.
+func Max(n float64) Rule {
+	return func(val string) error {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		if f > n {
+			return fmt.Errorf("must be at most %v", n)
+		}
+		return nil
+	}
+}
+
+// OneOf requires one of vals.
+func OneOf(vals ...string) Rule {
+	return func(val string) error {
+		for _, v := range vals {
+			if val == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(vals, ", "))
+	}
+}
+
+// NonEmpty requires a value that is not blank.
+func NonEmpty() Rule {
+	return func(val string) error {
+		if strings.TrimSpace(val) == "" {
+			return fmt.Errorf("must not be empty")
+		}
+		return nil
+	}
+}
+
+// MatchRegex requires a value matching pattern. It panics if pattern does not
+// compile, like regexp.MustCompile.
+func MatchRegex(pattern string) Rule {
+	re := regexp.MustCompile(pattern)
+	return func(val string) error {
+		if !re.MatchString(val) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	}
+}
+
+/*
+Validate checks the value of varName against rules and returns an error naming
+the variable and the first rule it breaks. An unset variable passes, since
+whether it is required is decided by the getter reading it; combine Validate
+with Require for variables without a default.
+
+	if err := Validate("PORT", Min(1), Max(65535)); err != nil {
+		return err
+	}
+*/
+func Validate(varName string, rules ...Rule) error {
+	return validate(lookup, varName, rules)
+}
+
+func validate(lookup lookupFunc, varName string, rules []Rule) error {
+	val, ok, err := lookup(varName)
+	if err != nil || !ok {
+		return err
+	}
+	for _, rule := range rules {
+		if err := rule(val); err != nil {
+			return NewValidationError(varName, val, err)
+		}
+	}
+	return nil
+}
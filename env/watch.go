@@ -0,0 +1,80 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Change describes an environment variable whose value changed.
+type Change struct {
+	Name string
+	Old  string
+	New  string
+	// Set reports whether the variable is set after the change.
+	Set bool
+	// Err is set when the new value can't be resolved.
+	Err error
+}
+
+type watchState struct {
+	val string
+	set bool
+	err string
+}
+
+/*
+Watch polls varNames every interval and sends a Change on the returned channel
+whenever one of them is set, unset or takes a new value. Values are resolved on
+every poll, so secret references such as "file:/run/secrets/token" are picked up
+when the file is rewritten. The channel is closed when ctx is done. Watch
+panics if interval is not positive.
+*/
+func Watch(ctx context.Context, interval time.Duration, varNames ...string) <-chan Change {
+	if interval <= 0 {
+		panic(fmt.Sprintf("env: non-positive interval %v for Watch", interval))
+	}
+	changes := make(chan Change)
+	read := func(name string) (watchState, error) {
+		val, ok, err := lookup(name)
+		s := watchState{val: val, set: ok}
+		if err != nil {
+			s.err = err.Error()
+		}
+		return s, err
+	}
+
+	states := make(map[string]watchState, len(varNames))
+	for _, name := range varNames {
+		states[name], _ = read(name)
+	}
+
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for _, name := range varNames {
+				s, err := read(name)
+				old := states[name]
+				if s == old {
+					continue
+				}
+				states[name] = s
+				c := Change{Name: name, Old: old.val, New: s.val, Set: s.set,
+					Err: err}
+				select {
+				case changes <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes
+}
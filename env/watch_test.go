@@ -0,0 +1,35 @@
+package env
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	t.Setenv("WATCH_LEVEL", "info")
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := Watch(ctx, time.Millisecond, "WATCH_LEVEL")
+	t.Setenv("WATCH_LEVEL", "debug")
+	c := <-changes
+	if c.Name != "WATCH_LEVEL" || c.Old != "info" || c.New != "debug" ||
+		!c.Set || c.Err != nil {
+		t.Errorf("Change = %+v", c)
+	}
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchPanicsOnNonPositiveInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Watch with interval %v didn't panic", d)
+				}
+			}()
+			Watch(context.Background(), d, "WATCH_LEVEL")
+		}()
+	}
+}
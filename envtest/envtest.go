@@ -0,0 +1,54 @@
+// Package envtest changes environment variables in tests and restores them
+// afterwards, so tests of environment driven configuration do not leak state
+// into each other.
+//
+// The process environment is shared, so none of these helpers may be used by
+// tests running in parallel. Tests of code reading through an env.Resolver can
+// avoid the process environment altogether by passing an env.Map source.
+package envtest
+
+import (
+	"os"
+	"testing"
+)
+
+// SetForTest sets key to value and restores its previous value, or unsets it,
+// when t and its subtests complete. Like t.Setenv, which it calls, it panics
+// in parallel tests.
+func SetForTest(t testing.TB, key, value string) {
+	t.Helper()
+	t.Setenv(key, value)
+}
+
+// UnsetForTest unsets key and restores its previous value when t and its
+// subtests complete. Like t.Setenv, it panics in parallel tests.
+func UnsetForTest(t testing.TB, key string) {
+	t.Helper()
+	// t.Setenv records the previous value and checks for t.Parallel.
+	t.Setenv(key, "")
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatalf("envtest: unsetting %s: %v", key, err)
+	}
+}
+
+// WithEnv sets vars, calls fn and restores the previous environment, even if
+// fn panics.
+func WithEnv(vars map[string]string, fn func()) {
+	for key, value := range vars {
+		defer restore(key)()
+		os.Setenv(key, value)
+	}
+	fn()
+}
+
+// restore returns a function resetting key to its current state.
+func restore(key string) func() {
+	prev, ok := os.LookupEnv(key)
+	return func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
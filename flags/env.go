@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/AnuragThePathak/my-go-packages/env"
+)
+
+type envBackend struct {
+	prefix string
+}
+
+// Env returns a backend reading flags from environment variables. A flag name
+// is upper cased, dashes become underscores and prefix is prepended, so with
+// the prefix "FEATURE_" the flag "new-checkout" is read from
+// FEATURE_NEW_CHECKOUT. A value is either a boolean such as "true" or a
+// rollout percentage such as "25%".
+func Env(prefix string) Backend {
+	return envBackend{prefix: prefix}
+}
+
+func (e envBackend) Flag(name string) (Flag, bool, error) {
+	varName := e.prefix +
+		strings.ReplaceAll(strings.ToUpper(name), "-", "_")
+	val, err := env.GetEnv(varName, "")
+	if err != nil || val == "" {
+		return Flag{}, false, err
+	}
+	if pct, ok := strings.CutSuffix(val, "%"); ok {
+		rollout, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil {
+			return Flag{}, false, env.NewParseError(varName, val,
+				"percentage", err)
+		}
+		return Flag{Rollout: rollout}, true, nil
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return Flag{}, false, env.NewParseError(varName, val, "bool", err)
+	}
+	return Flag{Enabled: enabled}, true, nil
+}
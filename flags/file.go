@@ -0,0 +1,112 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// File is a backend reading flags from a JSON object mapping flag names to
+// Flag values, e.g. {"new-checkout": {"rollout": 25}}. The file is checked
+// for changes every interval and reloaded; a broken file is logged and the
+// last good flags are kept.
+type File struct {
+	path string
+
+	mu      sync.RWMutex
+	flags   map[string]Flag
+	modTime time.Time
+	size    int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewFile loads path and starts checking it for changes every interval,
+// which must be positive.
+func NewFile(path string, interval time.Duration) (*File, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("non-positive flag file interval %v", interval)
+	}
+	f := &File{
+		path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	go f.watch(interval)
+	return f, nil
+}
+
+func (f *File) Flag(name string) (Flag, bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	flag, ok := f.flags[name]
+	return flag, ok, nil
+}
+
+// Shutdown stops watching the file.
+func (f *File) Shutdown(ctx context.Context) error {
+	f.once.Do(func() { close(f.stop) })
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *File) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	var flags map[string]Flag
+	if err := json.Unmarshal(b, &flags); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.flags, f.modTime, f.size = flags, info.ModTime(), info.Size()
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *File) watch(interval time.Duration) {
+	defer close(f.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(f.path)
+		if err != nil {
+			slog.Error("checking feature flag file", "path", f.path,
+				"error", err)
+			continue
+		}
+		f.mu.RLock()
+		changed := !info.ModTime().Equal(f.modTime) || info.Size() != f.size
+		f.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := f.load(); err != nil {
+			slog.Error("reloading feature flag file", "path", f.path,
+				"error", err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"new-checkout": {"rollout": 25}}`),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := NewFile(path, interval); err == nil {
+			t.Errorf("NewFile with interval %v succeeded; want an error",
+				interval)
+		}
+	}
+
+	f, err := NewFile(path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Shutdown(context.Background())
+	flag, ok, err := f.Flag("new-checkout")
+	if err != nil || !ok || flag.Rollout != 25 {
+		t.Errorf("Flag = %+v, %v, %v; want a 25%% rollout", flag, ok, err)
+	}
+}
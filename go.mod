@@ -1,3 +1,3 @@
 module github.com/AnuragThePathak/my-go-packages
 
-go 1.18
+go 1.21
@@ -0,0 +1,65 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// File is a Locker backed by advisory locks on files in a directory. The
+// operating system drops the lock if the process dies, so a crashed holder
+// never blocks others. Every process that competes for a lock must run on the
+// same host and see the same directory; use SQL across hosts.
+type File struct {
+	dir string
+}
+
+// NewFile returns a Locker keeping its lock files in dir, which must exist.
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+// TryAcquire implements Locker. The lock file for key is named key.lock.
+func (f *File) TryAcquire(_ context.Context, key string) (Lease, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) {
+		return nil, fmt.Errorf("invalid lock key %q", key)
+	}
+	fh, err := os.OpenFile(filepath.Join(f.dir, key+".lock"),
+		os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := tryLock(fh); err != nil {
+		fh.Close()
+		return nil, err
+	}
+	return &fileLease{key: key, fh: fh, lost: make(chan struct{})}, nil
+}
+
+type fileLease struct {
+	key  string
+	fh   *os.File
+	lost chan struct{}
+	once sync.Once
+	err  error
+}
+
+func (l *fileLease) Key() string { return l.key }
+
+func (l *fileLease) Lost() <-chan struct{} { return l.lost }
+
+func (l *fileLease) Release(context.Context) error {
+	l.once.Do(func() {
+		// Closing the file drops the lock even if unlocking fails.
+		err := unlock(l.fh)
+		if cerr := l.fh.Close(); err == nil {
+			err = cerr
+		}
+		l.err = err
+		close(l.lost)
+	})
+	return l.err
+}
@@ -0,0 +1,77 @@
+// Package lock provides leases on named locks, so that a job runs in only
+// one process at a time.
+//
+// Backends implement Locker: File for processes on one host and SQL for
+// replicas sharing a database. A Lease's Release method has the signature of a
+// shutdown hook, so it can be passed to signals.OnShutdown to give up the
+// lock when the process is told to stop.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotAcquired is returned by TryAcquire when the lock is held elsewhere.
+var ErrNotAcquired = errors.New("lock is held by another owner")
+
+// Locker hands out leases on named locks.
+type Locker interface {
+	// TryAcquire takes the lock named key without waiting. It returns
+	// ErrNotAcquired if the lock is already held.
+	TryAcquire(ctx context.Context, key string) (Lease, error)
+}
+
+// Lease is a held lock.
+type Lease interface {
+	// Key returns the name of the lock.
+	Key() string
+	// Lost returns a channel that is closed once the lease is released or
+	// can no longer be kept.
+	Lost() <-chan struct{}
+	// Release gives up the lock. Calling it more than once is a no-op.
+	Release(ctx context.Context) error
+}
+
+// Acquire calls l.TryAcquire every interval until it succeeds, fails with an
+// error other than ErrNotAcquired, or ctx is done.
+func Acquire(ctx context.Context, l Locker, key string,
+	interval time.Duration) (Lease, error) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		lease, err := l.TryAcquire(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lease, err
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Lead waits for the lock named key and runs fn while holding it, which makes
+// the process the leader for key. The context passed to fn is canceled when
+// ctx is done or the lease is lost. The lock is released when fn returns.
+func Lead(ctx context.Context, l Locker, key string, interval time.Duration,
+	fn func(ctx context.Context) error) error {
+	lease, err := Acquire(ctx, l, key, interval)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lease.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err = fn(ctx)
+	// Release even if ctx is already canceled.
+	return errors.Join(err, lease.Release(context.WithoutCancel(ctx)))
+}
@@ -0,0 +1,212 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Placeholder returns the bind parameter for the nth argument of a query,
+// counting from 1.
+type Placeholder func(n int) string
+
+// Question is the placeholder style of MySQL and SQLite.
+func Question(int) string { return "?" }
+
+// Dollar is the placeholder style of PostgreSQL.
+func Dollar(n int) string { return fmt.Sprintf("$%d", n) }
+
+/*
+SQL is a Locker keeping leases in a database/sql table, so processes on
+different hosts can share locks. The table has this layout:
+
+	CREATE TABLE locks (
+		name       VARCHAR(255) PRIMARY KEY,
+		owner      VARCHAR(32) NOT NULL,
+		expires_at BIGINT NOT NULL
+	);
+
+expires_at holds Unix nanoseconds. A lease is valid for the TTL and renewed
+every third of it while held, so a crashed holder blocks others for at most one
+TTL. If the lease can't be renewed before it expires, or another owner took it
+over, Lost is closed. The clocks of the competing hosts must agree to well
+within the TTL.
+*/
+type SQL struct {
+	db    *sql.DB
+	table string
+	ph    Placeholder
+	ttl   time.Duration
+}
+
+// NewSQL returns a Locker using table in db. A nil placeholder defaults to
+// Question and a ttl of zero or less to 30s.
+func NewSQL(db *sql.DB, table string, ph Placeholder,
+	ttl time.Duration) *SQL {
+	if ph == nil {
+		ph = Question
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &SQL{db: db, table: table, ph: ph, ttl: ttl}
+}
+
+// query replaces each "?" in q with the locker's placeholder and {table} with
+// the table name.
+func (s *SQL) query(q string) string {
+	q = strings.ReplaceAll(q, "{table}", s.table)
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString(s.ph(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// TryAcquire implements Locker. It takes over a row whose lease has expired
+// or inserts a new one.
+func (s *SQL) TryAcquire(ctx context.Context, key string) (Lease, error) {
+	if key == "" {
+		return nil, fmt.Errorf("invalid lock key %q", key)
+	}
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expires := now.Add(s.ttl)
+	res, err := s.db.ExecContext(ctx, s.query(`UPDATE {table}
+		SET owner = ?, expires_at = ? WHERE name = ? AND expires_at <= ?`),
+		owner, expires.UnixNano(), key, now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		_, err = s.db.ExecContext(ctx, s.query(`INSERT INTO {table}
+			(name, owner, expires_at) VALUES (?, ?, ?)`),
+			key, owner, expires.UnixNano())
+		if err != nil {
+			// The insert also fails if another owner holds the row, which
+			// can't be told apart from other errors in a portable way.
+			var held int
+			if qerr := s.db.QueryRowContext(ctx, s.query(`SELECT COUNT(*)
+				FROM {table} WHERE name = ?`), key).Scan(&held); qerr != nil {
+				return nil, errors.Join(err, qerr)
+			}
+			if held > 0 {
+				return nil, ErrNotAcquired
+			}
+			return nil, err
+		}
+	}
+	l := &sqlLease{
+		s:       s,
+		key:     key,
+		owner:   owner,
+		expires: expires,
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go l.renew()
+	return l, nil
+}
+
+type sqlLease struct {
+	s       *SQL
+	key     string
+	owner   string
+	expires time.Time
+	lost    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+
+	lostOnce sync.Once
+	once     sync.Once
+	err      error
+}
+
+func (l *sqlLease) Key() string { return l.key }
+
+func (l *sqlLease) Lost() <-chan struct{} { return l.lost }
+
+func (l *sqlLease) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+// renew extends the lease every third of the TTL until it is released or
+// lost. Failed renewals are retried until the lease expires.
+func (l *sqlLease) renew() {
+	defer close(l.done)
+	t := time.NewTicker(l.s.ttl / 3)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), l.s.ttl/3)
+		expires := time.Now().Add(l.s.ttl)
+		n, err := l.exec(ctx, `UPDATE {table} SET expires_at = ?
+			WHERE name = ? AND owner = ?`, expires.UnixNano())
+		cancel()
+		switch {
+		case err == nil && n == 1:
+			l.expires = expires
+		case err == nil || !time.Now().Before(l.expires):
+			// Another owner took the lock over, or it expired while the
+			// database was unreachable.
+			l.markLost()
+			return
+		}
+	}
+}
+
+// exec runs q with args followed by the lease's key and owner and returns
+// the number of rows affected.
+func (l *sqlLease) exec(ctx context.Context, q string, args ...any) (int64,
+	error) {
+	res, err := l.s.db.ExecContext(ctx, l.s.query(q),
+		append(args, l.key, l.owner)...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Release stops renewing and deletes the row if the lease still owns it.
+func (l *sqlLease) Release(ctx context.Context) error {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		_, l.err = l.exec(ctx, `DELETE FROM {table}
+			WHERE name = ? AND owner = ?`)
+		l.markLost()
+	})
+	return l.err
+}
+
+func newOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
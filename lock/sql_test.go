@@ -0,0 +1,211 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is a database/sql driver understanding only the queries of SQL, with
+// a switch to make every query fail.
+type fakeDB struct {
+	mu   sync.Mutex
+	rows map[string]fakeRow
+	down bool
+}
+
+type fakeRow struct {
+	owner   string
+	expires int64
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{db}, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver { return nil }
+
+func (db *fakeDB) setDown(down bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.down = down
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c fakeConn) ExecContext(_ context.Context, q string,
+	args []driver.NamedValue) (driver.Result, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.down {
+		return nil, errors.New("connection refused")
+	}
+	arg := func(i int) any { return args[i].Value }
+	q = strings.Join(strings.Fields(q), " ")
+	var n int64
+	switch {
+	case strings.HasPrefix(q, "UPDATE locks SET owner"):
+		name := arg(2).(string)
+		if r, ok := db.rows[name]; ok && r.expires <= arg(3).(int64) {
+			db.rows[name] = fakeRow{arg(0).(string), arg(1).(int64)}
+			n = 1
+		}
+	case strings.HasPrefix(q, "INSERT"):
+		name := arg(0).(string)
+		if _, ok := db.rows[name]; ok {
+			return nil, errors.New("duplicate key")
+		}
+		db.rows[name] = fakeRow{arg(1).(string), arg(2).(int64)}
+		n = 1
+	case strings.HasPrefix(q, "UPDATE locks SET expires_at"):
+		name := arg(1).(string)
+		if r, ok := db.rows[name]; ok && r.owner == arg(2).(string) {
+			db.rows[name] = fakeRow{r.owner, arg(0).(int64)}
+			n = 1
+		}
+	case strings.HasPrefix(q, "DELETE"):
+		name := arg(0).(string)
+		if r, ok := db.rows[name]; ok && r.owner == arg(1).(string) {
+			delete(db.rows, name)
+			n = 1
+		}
+	default:
+		return nil, errors.New("unexpected query " + q)
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, q string,
+	args []driver.NamedValue) (driver.Rows, error) {
+	db := c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.down {
+		return nil, errors.New("connection refused")
+	}
+	var count int64
+	if _, ok := db.rows[args[0].Value.(string)]; ok {
+		count = 1
+	}
+	return &countRows{count: count}, nil
+}
+
+type countRows struct {
+	count int64
+	read  bool
+}
+
+func (*countRows) Columns() []string { return []string{"count"} }
+
+func (*countRows) Close() error { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.count
+	return nil
+}
+
+func newFakeSQL(ttl time.Duration) (*SQL, *fakeDB) {
+	fake := &fakeDB{rows: make(map[string]fakeRow)}
+	return NewSQL(sql.OpenDB(fake), "locks", nil, ttl), fake
+}
+
+func TestSQLExclusive(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newFakeSQL(time.Minute)
+	lease, err := l.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryAcquire(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("second TryAcquire = %v; want ErrNotAcquired", err)
+	}
+	if err := lease.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lease.Lost():
+	default:
+		t.Error("Lost isn't closed after Release")
+	}
+	other, err := l.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("TryAcquire after Release = %v", err)
+	}
+	other.Release(ctx)
+}
+
+func TestSQLRenewsAndTakesOverExpired(t *testing.T) {
+	ctx := context.Background()
+	l, fake := newFakeSQL(30 * time.Millisecond)
+	lease, err := l.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Renewals keep the lease past its first expiry.
+	time.Sleep(100 * time.Millisecond)
+	if _, err := l.TryAcquire(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("TryAcquire of a renewed lease = %v; want ErrNotAcquired",
+			err)
+	}
+
+	// While the database is down the lease can't be renewed and is lost
+	// once it expires.
+	fake.setDown(true)
+	select {
+	case <-lease.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost isn't closed after the lease expired")
+	}
+	fake.setDown(false)
+	other, err := l.TryAcquire(ctx, "job")
+	if err != nil {
+		t.Fatalf("TryAcquire of an expired lease = %v", err)
+	}
+	defer other.Release(ctx)
+	// Releasing the lost lease leaves the new owner's row alone.
+	if err := lease.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.TryAcquire(ctx, "job"); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("TryAcquire = %v; want ErrNotAcquired", err)
+	}
+}
+
+func TestLeadStopsWhenLeaseIsLost(t *testing.T) {
+	l, fake := newFakeSQL(30 * time.Millisecond)
+	err := Lead(context.Background(), l, "job", time.Millisecond,
+		func(ctx context.Context) error {
+			fake.setDown(true)
+			defer fake.setDown(false)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+				return errors.New("fn wasn't canceled")
+			}
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+// Package log builds structured slog loggers with a consistent setup across
+// services.
+package log
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/AnuragThePathak/my-go-packages/env"
+)
+
+// Config describes how a logger is built. The zero value gives a text logger
+// at info level writing to stderr.
+type Config struct {
+	// Format is either "text" or "json".
+	Format string
+	Level  slog.Level
+	// Service and Version, when set, are attached to every record.
+	Service   string
+	Version   string
+	AddSource bool
+	// Output defaults to os.Stderr.
+	Output io.Writer
+}
+
+// New returns a logger built from cfg.
+func New(cfg Config) *slog.Logger {
+	out := cfg.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	opts := &slog.HandlerOptions{Level: cfg.Level, AddSource: cfg.AddSource}
+
+	var h slog.Handler
+	if strings.EqualFold(cfg.Format, "json") {
+		h = slog.NewJSONHandler(out, opts)
+	} else {
+		h = slog.NewTextHandler(out, opts)
+	}
+
+	logger := slog.New(h)
+	if cfg.Service != "" {
+		logger = logger.With("service", cfg.Service)
+	}
+	if cfg.Version != "" {
+		logger = logger.With("version", cfg.Version)
+	}
+	return logger
+}
+
+// ConfigFromEnv reads a Config from LOG_FORMAT, LOG_LEVEL, LOG_SOURCE,
+// SERVICE_NAME and SERVICE_VERSION. Unset variables keep their zero value.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	var err error
+	if cfg.Format, err = env.GetEnv("LOG_FORMAT", "text"); err != nil {
+		return cfg, err
+	}
+	format := strings.ToLower(cfg.Format)
+	if format != "text" && format != "json" {
+		return cfg, env.NewValidationError("LOG_FORMAT", cfg.Format,
+			errors.New("must be text or json"))
+	}
+	level, err := env.GetEnv("LOG_LEVEL", "info")
+	if err != nil {
+		return cfg, err
+	}
+	if err := cfg.Level.UnmarshalText([]byte(level)); err != nil {
+		return cfg, env.NewParseError("LOG_LEVEL", level, "log level", err)
+	}
+	if cfg.AddSource, err = env.GetEnvAsBool("LOG_SOURCE", false); err != nil {
+		return cfg, err
+	}
+	if cfg.Service, err = env.GetEnv("SERVICE_NAME", ""); err != nil {
+		return cfg, err
+	}
+	if cfg.Version, err = env.GetEnv("SERVICE_VERSION", ""); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// SetDefaultFromEnv builds a logger from the environment, installs it as the
+// slog default and returns it.
+func SetDefaultFromEnv() (*slog.Logger, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	logger := New(cfg)
+	slog.SetDefault(logger)
+	return logger, nil
+}
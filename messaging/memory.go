@@ -0,0 +1,93 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory is an in-process broker. Every subscriber of a topic receives each
+// message published to it; handler errors are not retried.
+type Memory struct {
+	buffer int
+
+	mu   sync.RWMutex
+	subs map[string][]*memorySub
+}
+
+type memorySub struct {
+	ch   chan Message
+	done chan struct{}
+}
+
+// NewMemory returns a broker whose subscribers buffer up to buffer messages
+// before Publish blocks.
+func NewMemory(buffer int) *Memory {
+	return &Memory{buffer: buffer, subs: make(map[string][]*memorySub)}
+}
+
+// Publish delivers msg to the current subscribers of msg.Topic, blocking
+// while a subscriber's buffer is full. It returns ctx.Err() if ctx is done
+// first.
+func (m *Memory) Publish(ctx context.Context, msg Message) error {
+	// remove replaces the slice rather than editing it in place, so it can
+	// be read without the lock, which must not be held while blocked on a
+	// subscriber.
+	m.mu.RLock()
+	subs := m.subs[msg.Topic]
+	m.mu.RUnlock()
+	for _, s := range subs {
+		select {
+		case s.ch <- msg:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe implements Subscriber. Messages already buffered when ctx is
+// canceled are still handled before it returns.
+func (m *Memory) Subscribe(ctx context.Context, topic string, h Handler) error {
+	s := &memorySub{
+		ch:   make(chan Message, m.buffer),
+		done: make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.subs[topic] = append(m.subs[topic], s)
+	m.mu.Unlock()
+
+	hctx := context.WithoutCancel(ctx)
+	for {
+		select {
+		case msg := <-s.ch:
+			_ = h(hctx, msg)
+		case <-ctx.Done():
+			close(s.done)
+			m.remove(topic, s)
+			for {
+				select {
+				case msg := <-s.ch:
+					_ = h(hctx, msg)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (m *Memory) remove(topic string, s *memorySub) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs := m.subs[topic]
+	for i, other := range subs {
+		if other == s {
+			m.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(m.subs[topic]) == 0 {
+		delete(m.subs, topic)
+	}
+}
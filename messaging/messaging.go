@@ -0,0 +1,131 @@
+// Package messaging defines publish/subscribe interfaces, an in-memory
+// broker and a Consumer that lets message handlers finish on shutdown.
+//
+// Adapters for brokers such as NATS or Kafka implement Publisher and
+// Subscriber; the Consumer works with any of them.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrClosed is returned by Handle after Shutdown has been called.
+var ErrClosed = errors.New("consumer is shut down")
+
+// Message is a single published message.
+type Message struct {
+	Topic  string
+	Key    string
+	Data   []byte
+	Header map[string]string
+}
+
+// Handler processes a message. Whether a returned error leads to redelivery
+// is up to the Subscriber.
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher sends messages to a topic.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Subscriber delivers messages published to topic to h until ctx is
+// canceled. Subscribe blocks, and must not return before the handler calls it
+// started have returned. Canceling ctx stops delivery; it must not cancel the
+// context passed to h.
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic string, h Handler) error
+}
+
+// Consumer runs handlers on a Subscriber and stops them gracefully.
+type Consumer struct {
+	sub    Subscriber
+	logger *slog.Logger
+
+	subCtx      context.Context
+	stopSubs    context.CancelFunc
+	handlerCtx  context.Context
+	stopHandles context.CancelFunc
+	wg          sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewConsumer returns a consumer reading from sub. A nil logger defaults to
+// slog.Default().
+func NewConsumer(sub Subscriber, logger *slog.Logger) *Consumer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	c := &Consumer{sub: sub, logger: logger}
+	c.subCtx, c.stopSubs = context.WithCancel(context.Background())
+	c.handlerCtx, c.stopHandles = context.WithCancel(context.Background())
+	return c
+}
+
+// Handle subscribes h to topic in the background. Handler errors and panics
+// are logged.
+func (c *Consumer) Handle(topic string, h Handler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		err := c.sub.Subscribe(c.subCtx, topic, c.wrap(topic, h))
+		if err != nil {
+			c.logger.Error("subscription failed", "topic", topic,
+				"error", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops receiving messages and waits for running handlers to
+// return. If ctx is done first, the handlers' context is canceled and
+// ctx.Err() is returned.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.stopSubs()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		c.stopHandles()
+		return nil
+	case <-ctx.Done():
+		c.stopHandles()
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) wrap(topic string, h Handler) Handler {
+	return func(_ context.Context, msg Message) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				c.logger.Error("message handler panicked", "topic", topic,
+					"panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+				err = fmt.Errorf("handler panicked: %v", r)
+			}
+		}()
+		if err = h(c.handlerCtx, msg); err != nil {
+			c.logger.Error("message handler failed", "topic", topic,
+				"error", err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,27 @@
+package os
+
+import "github.com/AnuragThePathak/my-go-packages/env"
+
+// The environment variable helpers below moved to the env package. They are
+// kept as forwarders so existing imports keep compiling.
+
+// GetEnv forwards to env.GetEnv.
+//
+// Deprecated: Use env.GetEnv.
+func GetEnv(varName string, params ...string) (string, error) {
+	return env.GetEnv(varName, params...)
+}
+
+// GetEnvAsInt forwards to env.GetEnvAsInt.
+//
+// Deprecated: Use env.GetEnvAsInt.
+func GetEnvAsInt(varName string, params ...int) (int, error) {
+	return env.GetEnvAsInt(varName, params...)
+}
+
+// GetEnvAsBool forwards to env.GetEnvAsBool.
+//
+// Deprecated: Use env.GetEnvAsBool.
+func GetEnvAsBool(varName string, params ...bool) (bool, error) {
+	return env.GetEnvAsBool(varName, params...)
+}
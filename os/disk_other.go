@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package os
+
+import "errors"
+
+// DiskUsage is not supported on this platform and always returns
+// errors.ErrUnsupported.
+func DiskUsage(path string) (Usage, error) {
+	return Usage{}, errors.ErrUnsupported
+}
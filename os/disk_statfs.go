@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package os
+
+import "syscall"
+
+// DiskUsage returns the space of the file system containing path.
+func DiskUsage(path string) (Usage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return Usage{}, err
+	}
+	bsize := uint64(st.Bsize)
+	u := Usage{
+		Total:     uint64(st.Blocks) * bsize,
+		Free:      uint64(st.Bfree) * bsize,
+		Available: uint64(st.Bavail) * bsize,
+	}
+	u.Used = u.Total - u.Free
+	return u, nil
+}
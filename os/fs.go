@@ -0,0 +1,117 @@
+// Package os provides file system helpers. Its environment variable getters
+// moved to the env package and remain here as deprecated forwarders.
+package os
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+/*
+EnsureDir creates path and any missing parents with perm, like os.MkdirAll,
+and returns an error if path exists but is not a directory.
+*/
+func EnsureDir(path string, perm fs.FileMode) error {
+	if err := os.MkdirAll(path, perm); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+/*
+FileExists reports whether path exists and is a regular file. A missing file is
+not an error; other failures to stat path are.
+*/
+func FileExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Mode().IsRegular(), nil
+}
+
+/*
+AtomicWriteFile writes data to a temporary file next to path and renames it
+over path, so readers see either the old or the new contents and never a
+partially written file.
+*/
+func AtomicWriteFile(path string, data []byte, perm fs.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+/*
+CopyFile copies src to dst, keeping the permission bits of src. dst is written
+atomically like with AtomicWriteFile.
+*/
+func CopyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+	return writeAtomic(dst, info.Mode().Perm(), func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+func writeAtomic(path string, perm fs.FileMode, write func(io.Writer) error) (
+	err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Usage describes the space of a file system in bytes.
+type Usage struct {
+	Total uint64
+	Free  uint64
+	// Available is the free space usable by unprivileged users.
+	Available uint64
+	Used      uint64
+}
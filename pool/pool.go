@@ -0,0 +1,174 @@
+// Package pool provides a bounded worker pool that drains its queue on
+// shutdown.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Submit after Shutdown has been called.
+var ErrClosed = errors.New("pool is shut down")
+
+// Task is a unit of work. The context is canceled if Shutdown gives up
+// waiting.
+type Task func(ctx context.Context) error
+
+// Config configures a Pool.
+type Config struct {
+	// Workers is the number of tasks run concurrently. Defaults to 1.
+	Workers int
+	// QueueSize is the number of tasks that can wait for a worker before
+	// Submit blocks.
+	QueueSize int
+	// Logger reports failed and panicking tasks. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Stats is a snapshot of the pool's counters.
+type Stats struct {
+	Submitted int64
+	Completed int64
+	Failed    int64
+	Panicked  int64
+	Running   int64
+	Queued    int64
+}
+
+// Pool runs submitted tasks on a fixed number of workers.
+type Pool struct {
+	logger *slog.Logger
+	queue  chan Task
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Submit registers in submitters under mu and never holds mu while
+	// blocked, so Shutdown can close closing right away and only close
+	// queue once every pending Submit has returned.
+	mu         sync.Mutex
+	closed     bool
+	closing    chan struct{}
+	submitters sync.WaitGroup
+	closeQueue sync.Once
+
+	submitted, completed, failed, panicked, running atomic.Int64
+}
+
+// New starts a pool with the given configuration.
+func New(cfg Config) *Pool {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		logger:  cfg.Logger,
+		queue:   make(chan Task, cfg.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues task, blocking while the queue is full. It returns ctx.Err()
+// if ctx is done first and ErrClosed if the pool is shut down, including while
+// it waits.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.submitters.Add(1)
+	p.mu.Unlock()
+	defer p.submitters.Done()
+
+	select {
+	case p.queue <- task:
+		p.submitted.Add(1)
+		return nil
+	case <-p.closing:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to
+// finish. If ctx is done first, the tasks' context is canceled and ctx.Err()
+// is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.submitters.Wait()
+		p.closeQueue.Do(func() { close(p.queue) })
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current counters.
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Failed:    p.failed.Load(),
+		Panicked:  p.panicked.Load(),
+		Running:   p.running.Load(),
+		Queued:    int64(len(p.queue)),
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for task := range p.queue {
+		p.run(task)
+	}
+}
+
+func (p *Pool) run(task Task) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+	defer func() {
+		if r := recover(); r != nil {
+			p.panicked.Add(1)
+			p.logger.Error("task panicked", "panic", fmt.Sprint(r),
+				"stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := task(p.ctx); err != nil {
+		p.failed.Add(1)
+		p.logger.Error("task failed", "error", err)
+		return
+	}
+	p.completed.Add(1)
+}
@@ -0,0 +1,61 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestShutdownWithBlockedSubmit(t *testing.T) {
+	p := New(Config{Workers: 1, QueueSize: 1,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	ctx := context.Background()
+	started := make(chan struct{})
+	wait := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if err := p.Submit(ctx, func(ctx context.Context) error {
+		close(started)
+		return wait(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// The worker is busy, so this fills the queue and the next Submit
+	// blocks.
+	if err := p.Submit(ctx, wait); err != nil {
+		t.Fatal(err)
+	}
+	blocked := make(chan error, 1)
+	go func() { blocked <- p.Submit(ctx, wait) }()
+	time.Sleep(10 * time.Millisecond)
+
+	sctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Shutdown(sctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v; want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v with a 20ms deadline", elapsed)
+	}
+	select {
+	case err := <-blocked:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("blocked Submit = %v; want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked Submit didn't return after Shutdown")
+	}
+	if err := p.Submit(ctx, wait); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Shutdown = %v; want ErrClosed", err)
+	}
+	// The canceled tasks let the pool drain.
+	if err := p.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown = %v", err)
+	}
+}
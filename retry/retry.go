@@ -0,0 +1,130 @@
+// Package retry runs operations with exponential backoff and jitter.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+type config struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+	jitter   float64
+	retryIf  func(error) bool
+}
+
+// Option configures Do.
+type Option func(*config)
+
+// Attempts sets the maximum number of calls, including the first one.
+// Defaults to 3.
+func Attempts(n int) Option {
+	return func(c *config) { c.attempts = n }
+}
+
+// Backoff sets the delay before the first retry and the cap the delay doubles
+// up to. Defaults to 100ms and 10s.
+func Backoff(initial, max time.Duration) Option {
+	return func(c *config) {
+		c.initial = initial
+		c.max = max
+	}
+}
+
+// Jitter randomizes every delay by up to the given fraction in either
+// direction, e.g. 0.2 for ±20%. Defaults to no jitter.
+func Jitter(fraction float64) Option {
+	return func(c *config) { c.jitter = fraction }
+}
+
+// If only retries errors for which retryable returns true. By default every
+// error except context cancellation is retried.
+func If(retryable func(error) bool) Option {
+	return func(c *config) { c.retryIf = retryable }
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Do returns it without retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a permanent or non-retryable error,
+// the attempts are exhausted or ctx is done. It returns the last error from
+// fn, joined with the context error if ctx ended the retries.
+func Do(ctx context.Context, fn func(context.Context) error,
+	opts ...Option) error {
+	c := config{
+		attempts: 3,
+		initial:  100 * time.Millisecond,
+		max:      10 * time.Second,
+		retryIf:  retryable,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	delay := c.initial
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= c.attempts || !c.retryIf(err) {
+			return err
+		}
+
+		t := time.NewTimer(withJitter(delay, c.jitter))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-t.C:
+		}
+		if delay *= 2; delay > c.max {
+			delay = c.max
+		}
+	}
+}
+
+func withJitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*fraction*float64(d))
+}
+
+func retryable(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsTimeout reports whether err is a network timeout.
+func IsTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsTemporary reports whether err is a network timeout or says of itself that
+// it is temporary.
+func IsTemporary(err error) bool {
+	var tmp interface{ Temporary() bool }
+	return IsTimeout(err) || errors.As(err, &tmp) && tmp.Temporary()
+}
@@ -0,0 +1,126 @@
+// Package run runs a group of goroutines that stop together: when one member
+// returns, every other member is interrupted.
+package run
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Member is a goroutine in a Group.
+type Member struct {
+	// Name identifies the member in logs.
+	Name string
+	// Execute runs the member. Its context is canceled with the group's
+	// first error when the group is interrupted.
+	Execute func(ctx context.Context) error
+	// Interrupt, if set, is called with the group's first error to make
+	// Execute return, e.g. by closing a listener.
+	Interrupt func(err error)
+	// Timeout bounds how long the group waits for Execute to return once
+	// interrupted. Zero waits forever.
+	Timeout time.Duration
+}
+
+// Group runs members until the first of them returns.
+type Group struct {
+	logger  *slog.Logger
+	members []Member
+}
+
+// New returns an empty group. A nil logger defaults to slog.Default().
+func New(logger *slog.Logger) *Group {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Group{logger: logger}
+}
+
+// Add adds m to the group. It must be called before Run.
+func (g *Group) Add(m Member) {
+	g.members = append(g.members, m)
+}
+
+// Run starts every member and waits until one returns or ctx is done. It then
+// interrupts the others and waits for them, up to their timeouts. It returns
+// the error of the first member to return, or context.Cause(ctx), which is a
+// *signals.SignalError when ctx comes from the signals package.
+func (g *Group) Run(ctx context.Context) error {
+	if len(g.members) == 0 {
+		return nil
+	}
+	type result struct {
+		i   int
+		err error
+	}
+	results := make(chan result, len(g.members))
+	cancels := make([]context.CancelCauseFunc, len(g.members))
+	for i, m := range g.members {
+		mctx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+		cancels[i] = cancel
+		go func(i int, m Member) {
+			results <- result{i: i, err: m.Execute(mctx)}
+		}(i, m)
+	}
+
+	const (
+		running = iota
+		stopped
+		abandoned
+	)
+	state := make([]int, len(g.members))
+	var first error
+	select {
+	case r := <-results:
+		first = r.err
+		state[r.i] = stopped
+		g.logger.Info("group member returned, interrupting group",
+			"member", g.members[r.i].Name, "error", r.err)
+	case <-ctx.Done():
+		first = context.Cause(ctx)
+		g.logger.Info("interrupting group", "cause", first)
+	}
+
+	expired := make(chan int, len(g.members))
+	remaining := 0
+	for i, m := range g.members {
+		if state[i] != running {
+			continue
+		}
+		remaining++
+		if m.Interrupt != nil {
+			m.Interrupt(first)
+		}
+		cancels[i](first)
+		if m.Timeout > 0 {
+			i := i
+			t := time.AfterFunc(m.Timeout, func() { expired <- i })
+			defer t.Stop()
+		}
+	}
+
+	start := time.Now()
+	for remaining > 0 {
+		select {
+		case r := <-results:
+			if state[r.i] != running {
+				continue
+			}
+			state[r.i] = stopped
+			remaining--
+			g.logger.Info("group member stopped",
+				"member", g.members[r.i].Name, "duration", time.Since(start),
+				"error", r.err)
+		case i := <-expired:
+			if state[i] != running {
+				continue
+			}
+			state[i] = abandoned
+			remaining--
+			g.logger.Error("group member did not stop in time",
+				"member", g.members[i].Name, "timeout", g.members[i].Timeout)
+		}
+	}
+	return first
+}
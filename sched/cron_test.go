@@ -0,0 +1,171 @@
+package sched
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+	}{
+		{"5", 0, 59, []int{5}},
+		{"1-3", 0, 59, []int{1, 2, 3}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"1-10/3", 0, 59, []int{1, 4, 7, 10}},
+		{"5/20", 0, 59, []int{5, 25, 45}},
+		{"1,3,5", 0, 59, []int{1, 3, 5}},
+		{"1-2,10-11", 1, 12, []int{1, 2, 10, 11}},
+		{"*", 1, 12, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"?", 0, 7, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		got, err := parseField(tt.field, tt.min, tt.max)
+		if err != nil {
+			t.Errorf("parseField(%q) error: %v", tt.field, err)
+			continue
+		}
+		var want uint64
+		for _, n := range tt.want {
+			want |= 1 << uint(n)
+		}
+		if got != want {
+			t.Errorf("parseField(%q) = %b; want %b", tt.field, got, want)
+		}
+	}
+}
+
+func TestParseFieldErrors(t *testing.T) {
+	tests := []struct {
+		field, want string
+	}{
+		{"60", "out of range"},
+		{"0", "out of range"},
+		{"5-1", "out of range"},
+		{"10-13", "out of range"},
+		{"a", "invalid cron value"},
+		{"", "invalid cron value"},
+		{"1-", "invalid cron range"},
+		{"*/0", "invalid cron step"},
+		{"*/x", "invalid cron step"},
+	}
+	for _, tt := range tests {
+		_, err := parseField(tt.field, 1, 12)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseField(%q) error = %v; want %q", tt.field, err,
+				tt.want)
+		}
+	}
+}
+
+func TestCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "* * * * * *", "@every",
+		"* * * * 8", "* 24 * * *", "0 0 * jan-dec *"} {
+		if _, err := Cron(expr); err == nil {
+			t.Errorf("Cron(%q) succeeded; want an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2026-10-14 is a Wednesday.
+	after := time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", date(2026, 10, 14, 10, 8)},
+		{"*/15 * * * *", date(2026, 10, 14, 10, 15)},
+		{"7 10 * * *", date(2026, 10, 15, 10, 7)},
+		{"@hourly", date(2026, 10, 14, 11, 0)},
+		{"@daily", date(2026, 10, 15, 0, 0)},
+		{"@monthly", date(2026, 11, 1, 0, 0)},
+		{"@yearly", date(2027, 1, 1, 0, 0)},
+		// Both 0 and 7 mean Sunday.
+		{"@weekly", date(2026, 10, 18, 0, 0)},
+		{"0 0 * * 7", date(2026, 10, 18, 0, 0)},
+		{"0 0 * * 5-7", date(2026, 10, 16, 0, 0)},
+		// Either day field matches when both are restricted.
+		{"0 0 13 * 5", date(2026, 10, 16, 0, 0)},
+		{"0 0 15 * 1", date(2026, 10, 15, 0, 0)},
+		// Both must match when one starts with *.
+		{"0 0 13 * *", date(2026, 11, 13, 0, 0)},
+		{"0 0 */10 * 1", date(2026, 12, 21, 0, 0)},
+		{"0 0 1 * */7", date(2026, 11, 1, 0, 0)},
+		{"0 12 29 2 *", date(2028, 2, 29, 12, 0)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := Cron(tt.expr)
+		if err != nil {
+			if !tt.want.IsZero() {
+				t.Errorf("Cron(%q) error: %v", tt.expr, err)
+			}
+			continue
+		}
+		if got := s.Next(after); !got.Equal(tt.want) {
+			t.Errorf("Cron(%q).Next = %v; want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	// at parses a wall clock time with its zone abbreviation, which tells the
+	// two 1:30s apart on November 1st.
+	at := func(value string) time.Time {
+		t.Helper()
+		tm, err := time.ParseInLocation("2006-01-02 15:04 MST", value, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	// Clocks go forward at 2:00 on March 8th and back at 2:00 on November
+	// 1st, 2026.
+	tests := []struct {
+		expr        string
+		after, want time.Time
+	}{
+		// 2:30 doesn't exist on March 8th.
+		{"30 2 * * *", at("2026-03-07 03:00 EST"), at("2026-03-09 02:30 EDT")},
+		{"0 * * * *", at("2026-03-08 01:30 EST"), at("2026-03-08 03:00 EDT")},
+		{"*/20 * * * *", at("2026-03-08 01:50 EST"),
+			at("2026-03-08 03:00 EDT")},
+		// 1:30 happens twice on November 1st.
+		{"30 1 * * *", at("2026-10-31 03:00 EDT"), at("2026-11-01 01:30 EDT")},
+		{"30 1 * * *", at("2026-11-01 01:30 EDT"), at("2026-11-02 01:30 EST")},
+		{"30 * * * *", at("2026-11-01 01:30 EDT"), at("2026-11-01 01:30 EST")},
+		{"0 2 * * *", at("2026-11-01 01:30 EDT"), at("2026-11-01 02:00 EST")},
+	}
+	for _, tt := range tests {
+		got := MustCron(tt.expr).Next(tt.after)
+		if !got.Equal(tt.want) {
+			t.Errorf("Cron(%q).Next(%v) = %v; want %v", tt.expr, tt.after,
+				got, tt.want)
+		}
+	}
+}
+
+func TestEveryPanics(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Every(%v) didn't panic", d)
+				}
+			}()
+			Every(d)
+		}()
+	}
+}
+
+func date(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
@@ -0,0 +1,159 @@
+// Package sched runs background jobs on intervals or cron schedules and waits
+// for running jobs on shutdown.
+package sched
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Add after Shutdown has been called.
+var ErrClosed = errors.New("scheduler is shut down")
+
+// Job is the work done on every run.
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	schedule Schedule
+	timeout  time.Duration
+	job      Job
+
+	mu      sync.Mutex
+	running bool
+}
+
+// Scheduler runs jobs on their schedules. A job never overlaps with itself:
+// a run that is due while the previous one is still going is skipped.
+type Scheduler struct {
+	logger *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New returns a scheduler logging to logger, or slog.Default() if nil.
+func New(logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Add schedules job under name. Each run gets a context with the given
+// timeout, or no timeout if it is zero. The job starts being scheduled
+// immediately.
+func (s *Scheduler) Add(name string, schedule Schedule, timeout time.Duration,
+	job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	e := &entry{name: name, schedule: schedule, timeout: timeout, job: job}
+	s.wg.Add(1)
+	go s.loop(e)
+	return nil
+}
+
+// Shutdown stops scheduling new runs and waits for running jobs to finish.
+// If ctx is done first, the jobs' context is canceled and ctx.Err() is
+// returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+	for {
+		now := time.Now()
+		next := e.schedule.Next(now)
+		if next.IsZero() {
+			s.logger.Warn("job has no next run", "job", e.name)
+			return
+		}
+		t := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		e.mu.Lock()
+		if e.running {
+			e.mu.Unlock()
+			s.logger.Warn("skipping job run, previous run still going",
+				"job", e.name)
+			continue
+		}
+		e.running = true
+		e.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.run(e)
+	}
+}
+
+func (s *Scheduler) run(e *entry) {
+	defer s.wg.Done()
+	defer func() {
+		e.mu.Lock()
+		e.running = false
+		e.mu.Unlock()
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("job panicked", "job", e.name, "panic",
+				fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+
+	ctx := s.ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	if err := e.job(ctx); err != nil {
+		s.logger.Error("job failed", "job", e.name,
+			"duration", time.Since(start), "error", err)
+		return
+	}
+	s.logger.Debug("job finished", "job", e.name,
+		"duration", time.Since(start))
+}
@@ -0,0 +1,62 @@
+package signals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+type hook struct {
+	name  string
+	order int
+	fn    func(context.Context) error
+}
+
+var (
+	hooksMu sync.Mutex
+	hooks   []hook
+)
+
+// OnShutdown registers fn to run when a signal trapped by one of the contexts
+// in this package fires. Hooks run one after another in ascending order;
+// hooks with the same order run in registration order.
+func OnShutdown(name string, order int, fn func(context.Context) error) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, hook{name: name, order: order, fn: fn})
+}
+
+// RunShutdownHooks runs the registered hooks and returns their errors joined.
+// Every hook runs at most once, so it is safe to call this when shutting down
+// for a reason other than a signal.
+func RunShutdownHooks(ctx context.Context) error {
+	return runHooks(ctx, slog.Default())
+}
+
+func runHooks(ctx context.Context, logger *slog.Logger) error {
+	hooksMu.Lock()
+	pending := hooks
+	hooks = nil
+	hooksMu.Unlock()
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].order < pending[j].order
+	})
+	var errs []error
+	for _, h := range pending {
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			logger.Error("shutdown hook failed", "name", h.name,
+				"duration", time.Since(start), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		logger.Info("shutdown hook finished", "name", h.name,
+			"duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
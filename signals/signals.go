@@ -2,37 +2,189 @@ package signals
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"os"
 	"os/signal"
-	"syscall"
+	"sync"
 	"time"
 )
 
+// Options configures ContextWith. The zero value traps the default signals and
+// forces an exit 10 seconds after the first signal. The default signals are
+// SIGHUP, SIGINT, SIGTERM and SIGQUIT, or os.Interrupt and SIGTERM on Windows.
+type Options struct {
+	// Signals to trap.
+	Signals []os.Signal
+	// GracePeriod is how long the process has to shut down after the first
+	// signal before it is forced to exit. A negative value disables the forced
+	// exit.
+	GracePeriod time.Duration
+	// ForceOnSecondSignal exits immediately when a second signal arrives.
+	ForceOnSecondSignal bool
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+	// Exit is called to force the exit. Defaults to os.Exit.
+	Exit func(code int)
+}
+
+// Context returns a context that is canceled when the process receives one of
+// the default signals.
 func Context() context.Context {
-	ctx, stopCtx := context.WithCancel(context.Background())
+	ctx, _ := ContextWithCancel(context.Background())
+	return ctx
+}
+
+// ContextWithCancel returns a copy of parent that is canceled when the process
+// receives one of sigs, or one of the default signals if none are given.
+// Calling the returned CancelFunc cancels the context and releases the signal
+// handler.
+func ContextWithCancel(parent context.Context, sigs ...os.Signal) (
+	context.Context, context.CancelFunc) {
+	return ContextWith(parent, Options{Signals: sigs})
+}
+
+// NotifyContextWithCause is like signal.NotifyContext but records the signal
+// as the context's cause, so context.Cause tells a signal apart from the
+// parent being canceled. The default signals are used if none are given. It
+// runs no shutdown hooks and never forces an exit.
+func NotifyContextWithCause(parent context.Context, sigs ...os.Signal) (
+	context.Context, context.CancelFunc) {
+	if len(sigs) == 0 {
+		sigs = defaultSignals
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, sigs...)
+	go func() {
+		select {
+		case s := <-sig:
+			cancel(&SignalError{Signal: s})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sig)
+		cancel(nil)
+	}
+}
+
+// ErrSignal matches every SignalError with errors.Is.
+var ErrSignal = errors.New("received signal")
+
+// SignalError is the cause of a context canceled by a signal, as returned by
+// context.Cause.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return "received signal " + e.Signal.String()
+}
+
+// Is reports whether target is ErrSignal.
+func (e *SignalError) Is(target error) bool {
+	return target == ErrSignal
+}
+
+// Signal returns the signal that canceled ctx, or nil if ctx was not canceled
+// by a signal.
+func Signal(ctx context.Context) os.Signal {
+	var e *SignalError
+	if errors.As(context.Cause(ctx), &e) {
+		return e.Signal
+	}
+	return nil
+}
+
+// ContextWith is like ContextWithCancel but lets the caller choose the grace
+// period, second-signal behaviour and logger. Calling the returned CancelFunc
+// also stops a pending forced exit. Hooks registered with OnShutdown run once
+// the signal fires, bounded by the grace period, and the CancelFunc waits for
+// them to finish, so it must not be called from a hook.
+func ContextWith(parent context.Context, opts Options) (context.Context,
+	context.CancelFunc) {
+	if len(opts.Signals) == 0 {
+		opts.Signals = defaultSignals
+	}
+	if opts.GracePeriod == 0 {
+		opts.GracePeriod = 10 * time.Second
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	exit := opts.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	ctx, stopCtx := context.WithCancelCause(parent)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	var once sync.Once
+	release := func() {
+		stopCtx(nil)
+		once.Do(func() { close(done) })
+		<-finished
+	}
 
 	// Listen for syscall signals for process to interrupt/quit
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM,
-		syscall.SIGQUIT)
+	signal.Notify(sig, opts.Signals...)
 	go func() {
-		<-sig
-		log.Println("Shutting down server...")
+		defer close(finished)
+		defer signal.Stop(sig)
+		var received os.Signal
+		select {
+		case received = <-sig:
+		case <-ctx.Done():
+			return
+		}
+		logger.Info("Shutting down server...", "signal", received.String())
 
-		// Shutdown signal with grace period of 10 seconds
-		shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
+		// Trigger graceful shutdown
+		stopCtx(&SignalError{Signal: received})
 
+		hookCtx, cancelHooks := context.WithCancel(context.Background())
+		defer cancelHooks()
+		var timeout <-chan time.Time
+		if opts.GracePeriod > 0 {
+			t := time.NewTimer(opts.GracePeriod)
+			defer t.Stop()
+			timeout = t.C
+			hookCtx, cancelHooks = context.WithTimeout(hookCtx,
+				opts.GracePeriod)
+			defer cancelHooks()
+		}
+		hooksDone := make(chan struct{})
 		go func() {
-			<-shutdownCtx.Done()
-			if shutdownCtx.Err() == context.DeadlineExceeded {
-				log.Fatal("graceful shutdown timed out.. forcing exit.")
-			}
+			defer close(hooksDone)
+			runHooks(hookCtx, logger)
 		}()
 
-		// Trigger graceful shutdown
-		stopCtx()
+		var second <-chan os.Signal
+		if opts.ForceOnSecondSignal {
+			second = sig
+		}
+		released := done
+		for hooksDone != nil || released != nil {
+			select {
+			case <-timeout:
+				logger.Error("graceful shutdown timed out.. forcing exit.")
+				exit(1)
+				return
+			case s := <-second:
+				logger.Error("received second signal.. forcing exit.",
+					"signal", s.String())
+				exit(1)
+				return
+			case <-hooksDone:
+				hooksDone = nil
+			case <-released:
+				released = nil
+			}
+		}
 	}()
-	return ctx
-}
\ No newline at end of file
+	return ctx, release
+}
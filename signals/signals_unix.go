@@ -0,0 +1,12 @@
+//go:build !windows
+
+package signals
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals are trapped when no signals are given.
+var defaultSignals = []os.Signal{syscall.SIGHUP, syscall.SIGINT,
+	syscall.SIGTERM, syscall.SIGQUIT}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package signals
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals are trapped when no signals are given. os.Interrupt is
+// delivered for Ctrl+C and Ctrl+Break, and the Go runtime delivers SIGTERM for
+// CTRL_CLOSE_EVENT, CTRL_LOGOFF_EVENT and CTRL_SHUTDOWN_EVENT. Windows ends the
+// process a few seconds after those events regardless of the grace period.
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Store that keeps tasks in the process, for development and
+// tests. Tasks are lost when the process exits.
+type Memory struct {
+	mu    sync.Mutex
+	tasks map[string]*memoryTask
+}
+
+type memoryTask struct {
+	Task
+	dead bool
+}
+
+// NewMemory returns an empty store.
+func NewMemory() *Memory {
+	return &Memory{tasks: make(map[string]*memoryTask)}
+}
+
+func (m *Memory) Enqueue(_ context.Context, queue string, payload []byte,
+	runAt time.Time) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[id] = &memoryTask{Task: Task{ID: id, Queue: queue,
+		Payload: payload, RunAt: runAt}}
+	return id, nil
+}
+
+func (m *Memory) Claim(_ context.Context, queue string,
+	visibility time.Duration) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var next *memoryTask
+	for _, t := range m.tasks {
+		if t.dead || t.Queue != queue || t.RunAt.After(now) {
+			continue
+		}
+		if next == nil || t.RunAt.Before(next.RunAt) {
+			next = t
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	lease, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	claimed := next.Task
+	claimed.Attempts++
+	claimed.Lease = lease
+	next.Attempts++
+	next.Lease = lease
+	next.RunAt = now.Add(visibility)
+	return &claimed, nil
+}
+
+func (m *Memory) Complete(_ context.Context, id, lease string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.claimed(id, lease); err != nil {
+		return err
+	}
+	delete(m.tasks, id)
+	return nil
+}
+
+func (m *Memory) Retry(_ context.Context, id, lease string, runAt time.Time,
+	lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, err := m.claimed(id, lease)
+	if err != nil {
+		return err
+	}
+	t.RunAt = runAt
+	t.LastError = lastError
+	return nil
+}
+
+func (m *Memory) Bury(_ context.Context, id, lease string,
+	lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, err := m.claimed(id, lease)
+	if err != nil {
+		return err
+	}
+	t.dead = true
+	t.LastError = lastError
+	return nil
+}
+
+// claimed returns the task id if lease is its current claim. m.mu must be
+// held.
+func (m *Memory) claimed(id, lease string) (*memoryTask, error) {
+	t, ok := m.tasks[id]
+	if !ok || t.dead || t.Lease != lease {
+		return nil, ErrLeaseLost
+	}
+	return t, nil
+}
+
+// Dead returns the tasks that were buried.
+func (m *Memory) Dead() []Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var dead []Task
+	for _, t := range m.tasks {
+		if t.dead {
+			dead = append(dead, t.Task)
+		}
+	}
+	return dead
+}
@@ -0,0 +1,166 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder returns the bind parameter for the nth argument of a query,
+// counting from 1.
+type Placeholder func(n int) string
+
+// Question is the placeholder style of MySQL and SQLite.
+func Question(int) string { return "?" }
+
+// Dollar is the placeholder style of PostgreSQL.
+func Dollar(n int) string { return fmt.Sprintf("$%d", n) }
+
+/*
+SQLStore keeps tasks in a database/sql table with this layout, using BYTEA for
+the payload on PostgreSQL:
+
+	CREATE TABLE tasks (
+		id         VARCHAR(32) PRIMARY KEY,
+		queue      VARCHAR(255) NOT NULL,
+		payload    BLOB,
+		status     VARCHAR(16) NOT NULL,
+		attempts   INTEGER NOT NULL,
+		run_at     BIGINT NOT NULL,
+		last_error TEXT NOT NULL
+	);
+	CREATE INDEX tasks_due ON tasks (queue, status, run_at);
+
+run_at holds Unix nanoseconds. Claims use a conditional update rather than row
+locks, so no dialect-specific locking is needed. A claim moves run_at to the
+end of the visibility timeout and uses that value as the task's lease.
+*/
+type SQLStore struct {
+	db    *sql.DB
+	table string
+	ph    Placeholder
+}
+
+// NewSQLStore returns a store using table in db. A nil placeholder defaults
+// to Question.
+func NewSQLStore(db *sql.DB, table string, ph Placeholder) *SQLStore {
+	if ph == nil {
+		ph = Question
+	}
+	return &SQLStore{db: db, table: table, ph: ph}
+}
+
+// query replaces each "?" in q with the store's placeholder and {table} with
+// the table name.
+func (s *SQLStore) query(q string) string {
+	q = strings.ReplaceAll(q, "{table}", s.table)
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString(s.ph(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *SQLStore) Enqueue(ctx context.Context, queue string, payload []byte,
+	runAt time.Time) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	_, err = s.db.ExecContext(ctx, s.query(`INSERT INTO {table}
+		(id, queue, payload, status, attempts, run_at, last_error)
+		VALUES (?, ?, ?, 'pending', 0, ?, '')`),
+		id, queue, payload, runAt.UnixNano())
+	return id, err
+}
+
+func (s *SQLStore) Claim(ctx context.Context, queue string,
+	visibility time.Duration) (*Task, error) {
+	// Another worker may claim the same row between the select and the
+	// update, in which case the next due task is tried.
+	for i := 0; i < 3; i++ {
+		now := time.Now()
+		t := &Task{Queue: queue}
+		var runAt int64
+		err := s.db.QueryRowContext(ctx, s.query(`SELECT id, payload,
+			attempts, run_at, last_error FROM {table}
+			WHERE queue = ? AND status = 'pending' AND run_at <= ?
+			ORDER BY run_at LIMIT 1`), queue, now.UnixNano()).
+			Scan(&t.ID, &t.Payload, &t.Attempts, &runAt, &t.LastError)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		claimedUntil := now.Add(visibility).UnixNano()
+		res, err := s.db.ExecContext(ctx, s.query(`UPDATE {table}
+			SET run_at = ?, attempts = attempts + 1
+			WHERE id = ? AND status = 'pending' AND run_at = ?`),
+			claimedUntil, t.ID, runAt)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 1 {
+			t.Attempts++
+			t.RunAt = time.Unix(0, runAt)
+			t.Lease = strconv.FormatInt(claimedUntil, 10)
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SQLStore) Complete(ctx context.Context, id, lease string) error {
+	return s.exec(ctx, lease, `DELETE FROM {table}
+		WHERE id = ? AND status = 'pending' AND run_at = ?`, id)
+}
+
+func (s *SQLStore) Retry(ctx context.Context, id, lease string, runAt time.Time,
+	lastError string) error {
+	return s.exec(ctx, lease, `UPDATE {table} SET run_at = ?, last_error = ?
+		WHERE id = ? AND status = 'pending' AND run_at = ?`,
+		runAt.UnixNano(), lastError, id)
+}
+
+func (s *SQLStore) Bury(ctx context.Context, id, lease string,
+	lastError string) error {
+	return s.exec(ctx, lease, `UPDATE {table}
+		SET status = 'dead', last_error = ?
+		WHERE id = ? AND status = 'pending' AND run_at = ?`, lastError, id)
+}
+
+// exec runs q, whose last parameter is the claim's run_at, and reports
+// ErrLeaseLost if no row matched.
+func (s *SQLStore) exec(ctx context.Context, lease, q string,
+	args ...any) error {
+	claimedUntil, err := strconv.ParseInt(lease, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid lease %q", lease)
+	}
+	res, err := s.db.ExecContext(ctx, s.query(q),
+		append(args, claimedUntil)...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package tasks runs durable background tasks stored in a database, with
+// retries, a dead-letter state and graceful shutdown. Delivery is at least
+// once, so handlers should be idempotent.
+package tasks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Task is a unit of work read from a Store.
+type Task struct {
+	ID      string
+	Queue   string
+	Payload []byte
+	// Attempts counts the runs of the task, including the current one.
+	Attempts int
+	RunAt    time.Time
+	// LastError is the error of the previous attempt, if any.
+	LastError string
+	// Lease identifies the claim that returned the task. Recording the
+	// outcome with a lease that has since expired fails with ErrLeaseLost.
+	Lease string
+}
+
+// ErrLeaseLost is returned when a task's outcome is recorded after its
+// visibility timeout expired and another claim may own it.
+var ErrLeaseLost = errors.New("task lease lost")
+
+// Store keeps tasks durably. Implementations must be safe for concurrent use
+// by several processes.
+type Store interface {
+	// Enqueue adds a task that becomes due at runAt and returns its ID.
+	Enqueue(ctx context.Context, queue string, payload []byte,
+		runAt time.Time) (string, error)
+	// Claim returns the next due task on queue with a new Lease and hides
+	// it from other claims for visibility, or returns nil if no task is due.
+	Claim(ctx context.Context, queue string, visibility time.Duration) (
+		*Task, error)
+	// The methods below record the outcome of the claim identified by
+	// lease. They return ErrLeaseLost if the task was claimed again since.
+
+	// Complete removes a finished task.
+	Complete(ctx context.Context, id, lease string) error
+	// Retry makes a failed task due again at runAt.
+	Retry(ctx context.Context, id, lease string, runAt time.Time,
+		lastError string) error
+	// Bury moves a task that will not be retried to the dead-letter state.
+	Bury(ctx context.Context, id, lease string, lastError string) error
+}
+
+// Handler runs a task. A returned error or panic schedules a retry.
+type Handler func(ctx context.Context, task Task) error
+
+// Config configures a Worker.
+type Config struct {
+	Store Store
+	Queue string
+	// Workers is the number of tasks run concurrently. Defaults to 1.
+	Workers int
+	// PollInterval is how long an idle worker waits before claiming again.
+	// Defaults to 1s.
+	PollInterval time.Duration
+	// Visibility is how long a claimed task is hidden from other workers.
+	// It also bounds a single run of the handler. Defaults to 1m.
+	Visibility time.Duration
+	// MaxAttempts is the number of runs after which a failing task is
+	// buried. Defaults to 5.
+	MaxAttempts int
+	// Backoff is the delay before the first retry and MaxBackoff the cap it
+	// doubles up to. They default to 1s and 1h.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Worker polls a queue and runs its tasks.
+type Worker struct {
+	cfg     Config
+	handler Handler
+	stop    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewWorker starts polling cfg.Queue and running h on its tasks.
+func NewWorker(cfg Config, h Handler) *Worker {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Visibility <= 0 {
+		cfg.Visibility = time.Minute
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Hour
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker{
+		cfg:     cfg,
+		handler: h,
+		stop:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	w.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go w.poll()
+	}
+	return w
+}
+
+// Shutdown stops claiming tasks and waits for running ones to finish. If ctx
+// is done first, the handlers' context is canceled and ctx.Err() is returned;
+// their tasks become due again once their visibility timeout passes.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.once.Do(func() { close(w.stop) })
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) poll() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+		task, err := w.cfg.Store.Claim(w.ctx, w.cfg.Queue, w.cfg.Visibility)
+		if err != nil {
+			w.cfg.Logger.Error("claiming task failed", "queue", w.cfg.Queue,
+				"error", err)
+		}
+		if task == nil {
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+		w.run(task)
+	}
+}
+
+func (w *Worker) run(task *Task) {
+	logger := w.cfg.Logger.With("queue", task.Queue, "task", task.ID,
+		"attempt", task.Attempts)
+	start := time.Now()
+	err := w.call(task)
+	if err != nil && w.ctx.Err() != nil {
+		// Shutdown gave up on the handler, so the task didn't fail on its
+		// own. Leave it to become due again when its lease expires.
+		logger.Warn("task interrupted by shutdown", "error", err)
+		return
+	}
+	// Record a success even if Shutdown gave up on the handler.
+	ctx := context.WithoutCancel(w.ctx)
+	switch {
+	case err == nil:
+		logger.Debug("task finished", "duration", time.Since(start))
+		err = w.cfg.Store.Complete(ctx, task.ID, task.Lease)
+	case task.Attempts >= w.cfg.MaxAttempts:
+		logger.Error("task failed, giving up", "error", err)
+		err = w.cfg.Store.Bury(ctx, task.ID, task.Lease, err.Error())
+	default:
+		delay := w.backoff(task.Attempts)
+		logger.Warn("task failed, retrying", "error", err, "delay", delay)
+		err = w.cfg.Store.Retry(ctx, task.ID, task.Lease,
+			time.Now().Add(delay), err.Error())
+	}
+	if errors.Is(err, ErrLeaseLost) {
+		logger.Warn("task ran past its visibility timeout, result dropped")
+	} else if err != nil {
+		logger.Error("recording task result failed", "error", err)
+	}
+}
+
+func (w *Worker) call(task *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.cfg.Logger.Error("task panicked", "queue", task.Queue,
+				"task", task.ID, "panic", fmt.Sprint(r),
+				"stack", string(debug.Stack()))
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(w.ctx, w.cfg.Visibility)
+	defer cancel()
+	return w.handler(ctx, *task)
+}
+
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.cfg.Backoff
+	for i := 1; i < attempts && d < w.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, w.cfg.MaxBackoff)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var quiet = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestShutdownLeavesInterruptedTasks(t *testing.T) {
+	m := NewMemory()
+	if _, err := m.Enqueue(context.Background(), "q", nil,
+		time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	w := NewWorker(Config{Store: m, Queue: "q", MaxAttempts: 1,
+		PollInterval: time.Millisecond, Visibility: 50 * time.Millisecond,
+		Logger: quiet}, func(ctx context.Context, _ Task) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	if err := w.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v; want context.DeadlineExceeded", err)
+	}
+	// Wait for the interrupted run to return.
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if dead := m.Dead(); len(dead) != 0 {
+		t.Fatalf("Dead() = %v; want no buried tasks", dead)
+	}
+	time.Sleep(50 * time.Millisecond)
+	task, err := m.Claim(context.Background(), "q", time.Minute)
+	if err != nil || task == nil {
+		t.Fatalf("Claim = %v, %v; want the interrupted task", task, err)
+	}
+	if task.Attempts != 2 || task.LastError != "" {
+		t.Errorf("task has Attempts %d, LastError %q; want 2, \"\"",
+			task.Attempts, task.LastError)
+	}
+}
+
+func TestMemoryLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	id, err := m.Enqueue(ctx, "q", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := m.Claim(ctx, "q", 0)
+	if err != nil || first == nil {
+		t.Fatalf("Claim = %v, %v", first, err)
+	}
+	// The visibility timeout has passed, so the task is claimed again.
+	second, err := m.Claim(ctx, "q", time.Minute)
+	if err != nil || second == nil {
+		t.Fatalf("second Claim = %v, %v", second, err)
+	}
+	if first.Lease == second.Lease {
+		t.Fatalf("both claims have lease %q", first.Lease)
+	}
+
+	if err := m.Complete(ctx, id, first.Lease); !errors.Is(err,
+		ErrLeaseLost) {
+		t.Errorf("Complete with an expired lease = %v; want ErrLeaseLost", err)
+	}
+	if err := m.Retry(ctx, id, first.Lease, time.Now(), "x"); !errors.Is(err,
+		ErrLeaseLost) {
+		t.Errorf("Retry with an expired lease = %v; want ErrLeaseLost", err)
+	}
+	if err := m.Bury(ctx, id, first.Lease, "x"); !errors.Is(err,
+		ErrLeaseLost) {
+		t.Errorf("Bury with an expired lease = %v; want ErrLeaseLost", err)
+	}
+	if err := m.Complete(ctx, id, second.Lease); err != nil {
+		t.Errorf("Complete with the current lease = %v", err)
+	}
+	if err := m.Complete(ctx, id, second.Lease); !errors.Is(err,
+		ErrLeaseLost) {
+		t.Errorf("Complete of a removed task = %v; want ErrLeaseLost", err)
+	}
+}
+
+func TestWorkerDropsResultAfterLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	id, err := m.Enqueue(ctx, "q", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	w := NewWorker(Config{Store: m, Queue: "q", PollInterval: time.Hour,
+		Visibility: 10 * time.Millisecond, Logger: quiet},
+		func(context.Context, Task) error {
+			close(started)
+			<-release
+			return nil
+		})
+	defer w.Shutdown(ctx)
+	<-started
+
+	// Another worker claims the task once its visibility timeout passes.
+	time.Sleep(20 * time.Millisecond)
+	task, err := m.Claim(ctx, "q", time.Minute)
+	if err != nil || task == nil {
+		t.Fatalf("Claim = %v, %v; want the expired task", task, err)
+	}
+	close(release)
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The first run's success must not remove the task from the new claim.
+	if err := m.Complete(ctx, id, task.Lease); err != nil {
+		t.Errorf("Complete with the new lease = %v; want nil", err)
+	}
+}